@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+)
+
+// runImportSDE implements "eve-notify import-sde [-o file] <sde.zip>", which
+// converts a downloaded copy of the official SDE into the compact universe
+// dataset. It works offline and returns the process exit code.
+func runImportSDE(args []string) int {
+	flags := flag.NewFlagSet("import-sde", flag.ContinueOnError)
+	output := flags.String("o", "", "output file (default: "+sde.FileName+" in the data directory)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: eve-notify import-sde [-o file] <sde.zip>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	outPath := *output
+	if outPath == "" {
+		dataDir, err := config.DataDir()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		outPath = filepath.Join(dataDir, sde.FileName)
+	}
+
+	data, err := sde.ImportFile(flags.Arg(0), outPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Printf("Imported %d regions, %d constellations, %d systems and %d ores into %s\n",
+		len(data.Regions), len(data.Constellations), len(data.Systems), len(data.Ores), outPath)
+	return 0
+}
@@ -3,15 +3,29 @@ package main
 
 import (
 	_ "image/png"
+	"os"
+	"path/filepath"
 
 	"github.com/FabricSoul/eve-notify/internal/tray"
 	"github.com/FabricSoul/eve-notify/internal/window"
+	"github.com/FabricSoul/eve-notify/pkg/bounty"
 	"github.com/FabricSoul/eve-notify/pkg/character"
+	"github.com/FabricSoul/eve-notify/pkg/combat"
 	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/esi"
+	"github.com/FabricSoul/eve-notify/pkg/esiwatch"
+	"github.com/FabricSoul/eve-notify/pkg/location"
 	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/mining"
 	"github.com/FabricSoul/eve-notify/pkg/monitoring"
 	"github.com/FabricSoul/eve-notify/pkg/notification"
+	"github.com/FabricSoul/eve-notify/pkg/profile"
+	"github.com/FabricSoul/eve-notify/pkg/route"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+	"github.com/FabricSoul/eve-notify/pkg/serverstatus"
+	"github.com/FabricSoul/eve-notify/pkg/sso"
 	"github.com/FabricSoul/eve-notify/pkg/subscription"
+	"github.com/FabricSoul/eve-notify/pkg/universewatch"
 	// We no longer need to import "github.com/getlantern/systray"
 )
 
@@ -19,28 +33,62 @@ func main() {
 	cleanup := logger.Init()
 	defer cleanup()
 
+	// Command line tools run without starting the app.
+	if len(os.Args) > 1 && os.Args[1] == "import-sde" {
+		code := runImportSDE(os.Args[2:])
+		cleanup()
+		os.Exit(code)
+	}
+
 	// 1. Create the Fyne app and window.
 	logger.Sugar.Infoln("Starting the Fyne app")
 	mainApp := window.NewApp()
 
 	configService := config.NewService(mainApp)
 	subService := subscription.NewService()
-	characaterService := character.NewService(mainApp,  configService, subService)
+	profileService := profile.NewService(mainApp, subService)
+	dataDir, err := configService.DataDir()
+	if err != nil {
+		logger.Sugar.Errorf("Data files will be written to the working directory: %v", err)
+	}
+	esiClient := esi.NewClient(configService.GetESIBaseURL(), configService.GetESIUserAgent(), filepath.Join(dataDir, "esi-cache"))
+	ssoService := sso.NewService(configService, dataDir)
+	characaterService := character.NewService(mainApp,  configService, subService, esiClient)
 	notificationService := notification.NewService(mainApp)
-	monitoringService := monitoring.NewService(configService, subService, notificationService)
+	serverStatusService := serverstatus.NewService(configService, esiClient, notificationService)
+	locationService := location.NewService(dataDir)
+	universe := sde.Load(dataDir)
+	routeService := route.NewService(universe)
+	miningService := mining.NewService(dataDir, universe, esiClient)
+	bountyService := bounty.NewService(dataDir)
+	combatService := combat.NewService(dataDir)
+	defer combatService.Save()
+	monitoringService := monitoring.NewService(configService, subService, profileService, notificationService, serverStatusService, locationService, universe, routeService, miningService, bountyService, combatService, characaterService.Index())
+
+	go serverStatusService.Start()
+	defer serverStatusService.Stop()
 
 	go monitoringService.Start()
 	defer monitoringService.Stop()
 
+	esiWatchService := esiwatch.NewService(configService, esiClient, ssoService, subService, notificationService, dataDir)
+	go esiWatchService.Start()
+	defer esiWatchService.Stop()
+
+	universeWatchService := universewatch.NewService(configService, esiClient, notificationService, dataDir)
+	go universeWatchService.Start()
+	defer universeWatchService.Stop()
+
 	configService.Init()
 
-	mainWindow := window.NewMainWindow(mainApp, characaterService, subService, notificationService)
-	settingsWindow := window.NewSettingsWindow(mainApp, configService, notificationService)
+	mainWindow := window.NewMainWindow(mainApp, characaterService, subService, profileService, ssoService, esiWatchService, locationService, routeService, miningService, notificationService, universe)
+	settingsWindow := window.NewSettingsWindow(mainApp, configService, profileService, notificationService, universe)
+	statisticsWindow := window.NewStatisticsWindow(mainApp, configService, characaterService, miningService, bountyService, combatService)
 
 
 
 	// 2. Set up the system tray menu using our refactored tray package.
-	tray.Setup(mainApp, mainWindow, settingsWindow)
+	tray.Setup(mainApp, mainWindow, settingsWindow, statisticsWindow, miningService.ResetHolds)
 
 	// 3. Hide the window initially to start as a tray-only application.
 	// You can change this to mainWindow.Show() if you want it visible on startup.
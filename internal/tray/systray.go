@@ -9,7 +9,7 @@ import (
 )
 
 // Setup configures and sets the system tray menu for the application.
-func Setup(app fyne.App, mainWindow fyne.Window, settingsWindow fyne.Window) {
+func Setup(app fyne.App, mainWindow fyne.Window, settingsWindow fyne.Window, statisticsWindow fyne.Window, resetOreHolds func()) {
 	// desk.App is the interface for desktop-specific features.
 	// We perform a type assertion to check if the app is running on a desktop.
 	if desk, ok := app.(desktop.App); ok {
@@ -25,6 +25,10 @@ func Setup(app fyne.App, mainWindow fyne.Window, settingsWindow fyne.Window) {
 				settingsWindow.Show()
 
 			}),
+			fyne.NewMenuItem("Statistics", func() {
+				statisticsWindow.Show()
+			}),
+			fyne.NewMenuItem("Reset Ore Holds", resetOreHolds),
 		)
 
 		// Set the menu for the system tray.
@@ -3,6 +3,9 @@ package window
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -14,8 +17,16 @@ import (
 	"fyne.io/fyne/v2/widget"
 	"github.com/FabricSoul/eve-notify/pkg/character"
 	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/esi"
+	"github.com/FabricSoul/eve-notify/pkg/esiwatch"
+	"github.com/FabricSoul/eve-notify/pkg/location"
 	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/mining"
 	"github.com/FabricSoul/eve-notify/pkg/notification"
+	"github.com/FabricSoul/eve-notify/pkg/profile"
+	"github.com/FabricSoul/eve-notify/pkg/route"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+	"github.com/FabricSoul/eve-notify/pkg/sso"
 	"github.com/FabricSoul/eve-notify/pkg/subscription"
 )
 
@@ -30,11 +41,11 @@ type refreshResult struct {
 }
 
 
-func NewMainWindow(app fyne.App, charSvc *character.Service, subSvc *subscription.Service, notifSvc *notification.Service) fyne.Window {
+func NewMainWindow(app fyne.App, charSvc *character.Service, subSvc *subscription.Service, profileSvc *profile.Service, ssoSvc *sso.Service, esiWatchSvc *esiwatch.Service, locationSvc *location.Service, routeSvc *route.Service, miningSvc *mining.Service, notifSvc *notification.Service, universe *sde.Universe) fyne.Window {
 	window := app.NewWindow("EVE Notify - Dashboard")
 
 	charData := binding.NewUntypedList()
-	rightPane := container.NewVBox(widget.NewLabel("Select a character to configure notifications."))
+	rightPane := container.NewStack(widget.NewLabel("Select a character to configure notifications."))
 
 	// --- PRE-DECLARE VARIABLES for use in closures ---
 	var buildRightPane func(char *character.Character)
@@ -97,22 +108,84 @@ func NewMainWindow(app fyne.App, charSvc *character.Service, subSvc *subscriptio
 	buildRightPane = func(char *character.Character) {
 		settings, isSubscribed := subSvc.GetSettings(char.ID)
 		if !isSubscribed {
-			settings = &subscription.NotificationSettings{}
+			settings = profileSvc.SettingsFor(char.ID)
 		}
+		assignedProfile := profileSvc.ProfileFor(char.ID)
 		charNameLabel := widget.NewLabel(fmt.Sprintf("Notifications for: %s", char.Name))
 		charNameLabel.TextStyle.Bold = true
-		check1 := widget.NewCheck("Alliance chat mentions", func(b bool) { settings.AllianceChat = b })
-		check2 := widget.NewCheck("Corp chat mentions", func(b bool) { settings.CorpChat = b })
-		check3 := widget.NewCheck("Local chat mentions", func(b bool) { settings.LocalChat = b })
-		check4 := widget.NewCheck("Mining storage full", func(b bool) { settings.MiningStorageFull = b })
-		check5 := widget.NewCheck("NPC agression stopped", func(b bool) { settings.NpcAggression = b })
-		check6 := widget.NewCheck("Player agression", func(b bool) { settings.PlayerAggression = b })
-		check7 := widget.NewCheck("Manual Autopilot", func(b bool) { settings.ManualAutopilot = b })
-		check1.SetChecked(settings.AllianceChat); check2.SetChecked(settings.CorpChat); check3.SetChecked(settings.LocalChat)
-		check4.SetChecked(settings.MiningStorageFull); check5.SetChecked(settings.NpcAggression); check6.SetChecked(settings.PlayerAggression)
-		check7.SetChecked(settings.ManualAutopilot)
-		formContainer := container.NewVBox(check1, check2, check3, check4, check5, check6, check7)
-		setContainerEnabled(formContainer, !isSubscribed)
+		sessionCount := len(charSvc.Sessions(char.ID, 7*24*time.Hour))
+		header := container.NewVBox(charNameLabel, widget.NewLabel(fmt.Sprintf("Sessions in the last 7 days: %d", sessionCount)))
+		if history := charSvc.NameHistory(char.ID); len(history) > 1 {
+			var previous []string
+			for _, record := range history[:len(history)-1] {
+				previous = append(previous, record.Name)
+			}
+			header.Add(widget.NewLabel("Previously known as: " + strings.Join(previous, ", ")))
+		}
+		if loc, ok := locationSvc.Get(char.ID); ok {
+			text := "Location: " + loc.System
+			if loc.Previous != "" {
+				text += fmt.Sprintf(" (from %s)", loc.Previous)
+			}
+			header.Add(widget.NewLabel(text))
+		}
+		if progress, ok := routeSvc.Progress(char.ID, locationSvc.System(char.ID)); ok {
+			header.Add(widget.NewLabel("Route: " + progress.Summary()))
+		}
+		if queue, ok := esiWatchSvc.SkillQueue(char.ID); ok {
+			header.Add(widget.NewLabel(skillQueueText(queue)))
+		}
+		for _, planet := range esiWatchSvc.Planets(char.ID) {
+			header.Add(widget.NewLabel(planetText(planet)))
+		}
+
+		// A character using a profile is configured through the profile itself.
+		formContainer := newSettingsForm(settings)
+		setContainerEnabled(formContainer, !isSubscribed && assignedProfile == "")
+
+		profileSelect := widget.NewSelect(append([]string{noProfile}, profileSvc.Profiles()...), nil)
+		if assignedProfile == "" {
+			profileSelect.SetSelected(noProfile)
+		} else {
+			profileSelect.SetSelected(assignedProfile)
+		}
+		profileSelect.OnChanged = func(name string) {
+			if name == noProfile {
+				name = ""
+			}
+			if name == profileSvc.ProfileFor(char.ID) {
+				return
+			}
+			profileSvc.AssignProfile(char.ID, name)
+			buildRightPane(char)
+		}
+
+		groupsEntry := widget.NewEntry()
+		groupsEntry.SetPlaceHolder("e.g. Miners, Haulers")
+		groupsEntry.SetText(strings.Join(profileSvc.GroupsFor(char.ID), ", "))
+		saveGroupsButton := widget.NewButton("Save", func() {
+			profileSvc.SetGroups(char.ID, strings.Split(groupsEntry.Text, ","))
+		})
+
+		destinationEntry := widget.NewEntry()
+		destinationEntry.SetPlaceHolder("Solar system; empty to clear")
+		destinationEntry.SetText(routeSvc.Destination(char.ID))
+		setDestinationButton := widget.NewButton("Set", func() {
+			if err := routeSvc.SetDestination(char.ID, strings.TrimSpace(destinationEntry.Text)); err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			buildRightPane(char)
+		})
+
+		profileForm := widget.NewForm(
+			widget.NewFormItem("Profile", profileSelect),
+			widget.NewFormItem("Groups", container.NewBorder(nil, nil, nil, saveGroupsButton, groupsEntry)),
+			widget.NewFormItem("Destination", container.NewBorder(nil, nil, nil, setDestinationButton, destinationEntry)),
+			widget.NewFormItem("Ore Hold", newHoldCapacityEntry(miningSvc, char.ID)),
+		)
+
+		authSection := newAuthorizationSection(app, window, ssoSvc, notifSvc, char, func() { buildRightPane(char) })
 
 		var actionButton *widget.Button
 		if isSubscribed {
@@ -127,8 +200,10 @@ func NewMainWindow(app fyne.App, charSvc *character.Service, subSvc *subscriptio
 				go refreshCharsWorker()
 			})
 		}
+		// The options scroll, so the action button stays on screen.
+		top := container.NewVBox(header, widget.NewSeparator(), profileForm, authSection, widget.NewSeparator())
 		rightPane.Objects = []fyne.CanvasObject{
-			charNameLabel, widget.NewSeparator(), formContainer, layout.NewSpacer(), actionButton,
+			container.NewBorder(top, actionButton, nil, nil, container.NewVScroll(formContainer)),
 		}
 		rightPane.Refresh()
 	}
@@ -149,12 +224,27 @@ func NewMainWindow(app fyne.App, charSvc *character.Service, subSvc *subscriptio
 	refreshButton := widget.NewButton("Refresh", func() {
 		go refreshCharsWorker()
 	})
-	leftPane := container.NewBorder(container.NewVBox(widget.NewLabel("Characters"), widget.NewSeparator()), refreshButton, nil, nil, charList)
+	profilesButton := widget.NewButton("Profiles...", func() {
+		showProfilesDialog(window, profileSvc)
+	})
+	groupsButton := widget.NewButton("Groups...", func() {
+		showGroupsDialog(window, profileSvc, notifSvc, func() { go refreshCharsWorker() })
+	})
+	leftButtons := container.NewGridWithColumns(3, refreshButton, profilesButton, groupsButton)
+	leftPane := container.NewBorder(container.NewVBox(widget.NewLabel("Characters"), widget.NewSeparator()), leftButtons, nil, nil, charList)
 
 	split := container.NewHSplit(leftPane, container.NewPadded(rightPane))
 	split.Offset = 0.3
 
-	window.SetContent(split)
+	if universe.Empty() {
+		// Without the static data, location, route, intel and security alerts stay off.
+		banner := widget.NewLabel("Universe data is not installed, so location, route, intel and security alerts are off. " + sde.ImportHint)
+		banner.Wrapping = fyne.TextWrapWord
+		banner.Importance = widget.WarningImportance
+		window.SetContent(container.NewBorder(banner, nil, nil, nil, split))
+	} else {
+		window.SetContent(split)
+	}
 	window.Resize(fyne.NewSize(1280, 720))
 
 	go refreshCharsWorker()
@@ -169,7 +259,7 @@ func NewMainWindow(app fyne.App, charSvc *character.Service, subSvc *subscriptio
 
 
 // NewSettingsWindow has been completely redesigned for a professional look.
-func NewSettingsWindow(app fyne.App, cfg *config.Service, notifSvc *notification.Service) fyne.Window {
+func NewSettingsWindow(app fyne.App, cfg *config.Service, profileSvc *profile.Service, notifSvc *notification.Service, universe *sde.Universe) fyne.Window {
 	logger.Sugar.Debugln("Creating settings window UI.")
 	window := app.NewWindow("Settings")
 
@@ -213,9 +303,109 @@ func NewSettingsWindow(app fyne.App, cfg *config.Service, notifSvc *notification
 
 	pathWidget := container.NewBorder(nil, nil, nil, changePathButton, logPathValue)
 
+	autoSubscribeCheck := widget.NewCheck("Subscribe characters when a new session starts", cfg.SetAutoSubscribe)
+	autoSubscribeCheck.SetChecked(cfg.GetAutoSubscribe())
+	serverStatusCheck := widget.NewCheck("Notify when Tranquility goes down or comes back", cfg.SetServerStatusAlerts)
+	serverStatusCheck.SetChecked(cfg.GetServerStatusAlerts())
+
+	// Universe watch filters: comma separated regions, constellations or systems.
+	incursionEntry := newPlaceFilterEntry(cfg.GetIncursionFilter(), cfg.SetIncursionFilter)
+	sovCampaignEntry := newPlaceFilterEntry(cfg.GetSovCampaignFilter(), cfg.SetSovCampaignFilter)
+	fwEntry := newPlaceFilterEntry(cfg.GetFWFilter(), cfg.SetFWFilter)
+
+	intelChannelsEntry := widget.NewEntry()
+	intelChannelsEntry.SetPlaceHolder("Channel names, comma separated")
+	intelChannelsEntry.SetText(cfg.GetIntelChannels())
+	intelChannelsEntry.OnChanged = cfg.SetIntelChannels
+	intelRangeEntry := newWholeNumberEntry(cfg.GetIntelRangeJumps(), "Jumps", "jumps", cfg.SetIntelRangeJumps)
+
+	templateEntry := widget.NewEntry()
+	templateEntry.SetPlaceHolder(notification.DefaultTemplate)
+	templateEntry.SetText(cfg.GetMessageTemplate())
+	templateEntry.Validator = func(text string) error {
+		_, err := notification.ParseTemplate(text)
+		return err
+	}
+	templateEntry.OnChanged = func(text string) {
+		if _, err := notification.ParseTemplate(text); err == nil {
+			cfg.SetMessageTemplate(text)
+		}
+	}
+
+	autoProfileSelect := widget.NewSelect(profileSvc.Profiles(), cfg.SetAutoSubscribeProfile)
+	autoProfileSelect.PlaceHolder = "Select a default profile"
+	autoProfileSelect.SetSelected(cfg.GetAutoSubscribeProfile())
+	profileSvc.OnProfilesChanged(func() {
+		autoProfileSelect.Options = profileSvc.Profiles()
+		autoProfileSelect.Refresh()
+	})
+
+	autoUnsubscribeEntry := newMinutesEntry(cfg.GetAutoUnsubscribeMinutes(), cfg.SetAutoUnsubscribeMinutes)
+	clientIdleEntry := newMinutesEntry(cfg.GetClientIdleMinutes(), cfg.SetClientIdleMinutes)
+	skillQueueWarnEntry := newMinutesEntry(cfg.GetSkillQueueWarnMinutes(), cfg.SetSkillQueueWarnMinutes)
+	planetWarnEntry := newMinutesEntry(cfg.GetPlanetWarnMinutes(), cfg.SetPlanetWarnMinutes)
+	cargoWarnEntry := newMinutesEntry(cfg.GetCargoWarnMinutes(), cfg.SetCargoWarnMinutes)
+	combatDPSEntry := newWholeNumberEntry(cfg.GetCombatDPSThreshold(), "DPS (0 = never)", "DPS", cfg.SetCombatDPSThreshold)
+	combatDPSSecondsEntry := newWholeNumberEntry(cfg.GetCombatDPSSeconds(), "Seconds", "seconds", cfg.SetCombatDPSSeconds)
+	marketFillStepEntry := newWholeNumberEntry(cfg.GetMarketFillStepPercent(), "Percent (0 = never)", "percent", cfg.SetMarketFillStepPercent)
+	walletThresholdEntry := newWholeNumberEntry(cfg.GetWalletThresholdMillions(), "Million ISK", "million ISK", cfg.SetWalletThresholdMillions)
+	mailLabelsGroup := newMailLabelsGroup(cfg)
+
+	// ESI settings are read once at startup.
+	esiBaseURLEntry := widget.NewEntry()
+	esiBaseURLEntry.SetPlaceHolder(esi.DefaultBaseURL)
+	esiBaseURLEntry.SetText(cfg.GetESIBaseURL())
+	esiBaseURLEntry.OnChanged = cfg.SetESIBaseURL
+	esiUserAgentEntry := widget.NewEntry()
+	esiUserAgentEntry.SetPlaceHolder(esi.DefaultUserAgent)
+	esiUserAgentEntry.SetText(cfg.GetESIUserAgent())
+	esiUserAgentEntry.OnChanged = cfg.SetESIUserAgent
+
+	ssoClientIDEntry := widget.NewEntry()
+	ssoClientIDEntry.SetPlaceHolder("Client ID from developers.eveonline.com")
+	ssoClientIDEntry.SetText(cfg.GetSSOClientID())
+	ssoClientIDEntry.OnChanged = cfg.SetSSOClientID
+	ssoPortEntry := widget.NewEntry()
+	ssoPortEntry.SetText(strconv.Itoa(cfg.GetSSOCallbackPort()))
+	ssoPortEntry.Validator = func(text string) error {
+		if n, err := strconv.Atoi(text); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("enter a port between 1 and 65535")
+		}
+		return nil
+	}
+	ssoPortEntry.OnChanged = func(text string) {
+		if n, err := strconv.Atoi(text); err == nil && n >= 1 && n <= 65535 {
+			cfg.SetSSOCallbackPort(n)
+		}
+	}
+
 	form := widget.NewForm(
 		widget.NewFormItem("EVE Log Path", pathWidget),
 		widget.NewFormItem("Audio Output", testSoundButton),
+		widget.NewFormItem("Auto-subscribe", autoSubscribeCheck),
+		widget.NewFormItem("Default Profile", autoProfileSelect),
+		widget.NewFormItem("Auto-unsubscribe After", autoUnsubscribeEntry),
+		widget.NewFormItem("Client Idle After", clientIdleEntry),
+		widget.NewFormItem("Skill Queue Warning", skillQueueWarnEntry),
+		widget.NewFormItem("PI Warning", planetWarnEntry),
+		widget.NewFormItem("Ore Hold Warning", cargoWarnEntry),
+		widget.NewFormItem("Incoming DPS Alert", combatDPSEntry),
+		widget.NewFormItem("Incoming DPS Over", combatDPSSecondsEntry),
+		widget.NewFormItem("Market Fill Step", marketFillStepEntry),
+		widget.NewFormItem("Wallet Alert Above", walletThresholdEntry),
+		widget.NewFormItem("Mail Labels", mailLabelsGroup),
+		&widget.FormItem{Text: "Message Template", Widget: templateEntry, HintText: "Fields: {{.Character}}, {{.System}}, {{.Message}}"},
+		widget.NewFormItem("Server Status", serverStatusCheck),
+		widget.NewFormItem("Incursions In", incursionEntry),
+		widget.NewFormItem("Sov Campaigns In", sovCampaignEntry),
+		widget.NewFormItem("FW Changes In", fwEntry),
+		widget.NewFormItem("Intel Channels", intelChannelsEntry),
+		widget.NewFormItem("Intel Range", intelRangeEntry),
+		&widget.FormItem{Text: "Universe Data", Widget: widget.NewLabel(universe.Summary()), HintText: "Used for security, route and intel alerts and ore volumes"},
+		widget.NewFormItem("ESI Base URL (restart)", esiBaseURLEntry),
+		widget.NewFormItem("ESI User-Agent (restart)", esiUserAgentEntry),
+		widget.NewFormItem("SSO Client ID", ssoClientIDEntry),
+		widget.NewFormItem("SSO Callback Port", ssoPortEntry),
 	)
 
 	btnClose := widget.NewButton("Close", func() {
@@ -236,15 +426,218 @@ func NewSettingsWindow(app fyne.App, cfg *config.Service, notifSvc *notification
 	bottomBar := container.NewHBox(layout.NewSpacer(), btnDefault, btnClose)
 
 content := container.NewPadded(
-		container.NewBorder(form, bottomBar, nil, nil),
+		container.NewBorder(nil, bottomBar, nil, nil, container.NewVScroll(form)),
 	)
 	window.SetContent(content)
 	window.Resize(fyne.NewSize(960, 540))
-	window.SetFixedSize(true)
 
 	return window
 }
 
+// newSettingsForm builds one checkbox per notification option, bound to settings.
+func newSettingsForm(settings *subscription.NotificationSettings) *fyne.Container {
+	options := []struct {
+		label string
+		value *bool
+	}{
+		{"Alliance chat mentions", &settings.AllianceChat},
+		{"Corp chat mentions", &settings.CorpChat},
+		{"Local chat mentions", &settings.LocalChat},
+		{"Mining storage full", &settings.MiningStorageFull},
+		{"Mining storage filling soon", &settings.CargoFullSoon},
+		{"Mining stopped", &settings.MiningIdle},
+		{"Ratting session summary", &settings.BountySummary},
+		{"High incoming DPS", &settings.IncomingDPS},
+		{"NPC agression stopped", &settings.NpcAggression},
+		{"Player agression", &settings.PlayerAggression},
+		{"Manual Autopilot", &settings.ManualAutopilot},
+		{"Autopilot arrival and stuck", &settings.AutopilotRoute},
+		{"Login and disconnect", &settings.SessionEvents},
+		{"Client idle", &settings.ClientIdle},
+		{"Entering lower security space", &settings.SecurityDrop},
+		{"Hostiles reported nearby in intel", &settings.IntelReports},
+		{"Structure under attack (ESI)", &settings.StructureAttacks},
+		{"Structure fuel low (ESI)", &settings.StructureFuel},
+		{"War declarations (ESI)", &settings.WarDeclarations},
+		{"Sovereignty changes (ESI)", &settings.SovereigntyChanges},
+		{"Skill trained (ESI)", &settings.SkillCompleted},
+		{"Skill queue running low (ESI)", &settings.SkillQueueLow},
+		{"Industry jobs finished (ESI)", &settings.IndustryJobs},
+		{"Include corporation jobs (ESI)", &settings.IndustryCorpJobs},
+		{"PI extractors stopping (ESI)", &settings.PlanetExtractors},
+		{"PI storage filling (ESI)", &settings.PlanetStorage},
+		{"Market order filled (ESI)", &settings.MarketFilled},
+		{"Market order partially filled (ESI)", &settings.MarketPartialFill},
+		{"Market order outbid (ESI)", &settings.MarketOutbid},
+		{"Market order expiring (ESI)", &settings.MarketExpiry},
+		{"New mail (ESI)", &settings.MailReceived},
+		{"Contracts (ESI)", &settings.Contracts},
+		{"Wallet income (ESI)", &settings.WalletJournal},
+	}
+
+	formContainer := container.NewVBox()
+	for _, option := range options {
+		value := option.value
+		check := widget.NewCheck(option.label, func(b bool) { *value = b })
+		check.SetChecked(*value)
+		formContainer.Add(check)
+		if value == &settings.SecurityDrop {
+			formContainer.Add(newSecurityThresholdSelect(settings))
+		}
+	}
+	return formContainer
+}
+
+// newSecurityThresholdSelect picks the security status below which entering
+// a system alerts.
+func newSecurityThresholdSelect(settings *subscription.NotificationSettings) *widget.Select {
+	labels := map[string]float64{}
+	var options []string
+	for tenths := 10; tenths >= 0; tenths-- {
+		threshold := float64(tenths) / 10
+		label := fmt.Sprintf("Alert below %.1f", threshold)
+		switch tenths {
+		case 5:
+			label += " (leaving high-sec)"
+		case 1:
+			label += " (entering null-sec)"
+		case 0:
+			label += " (negative security only)"
+		}
+		labels[label] = threshold
+		options = append(options, label)
+	}
+
+	thresholdSelect := widget.NewSelect(options, nil)
+	for _, label := range options {
+		if labels[label] == settings.SecurityThresholdOrDefault() {
+			thresholdSelect.SetSelected(label)
+		}
+	}
+	thresholdSelect.OnChanged = func(label string) {
+		threshold := labels[label]
+		settings.SecurityThreshold = &threshold
+	}
+	return thresholdSelect
+}
+
+// skillQueueText describes when a character's skill queue ends.
+func skillQueueText(queue esiwatch.SkillQueueState) string {
+	switch {
+	case queue.Paused:
+		return "Skill queue: paused"
+	case queue.End.IsZero():
+		return "Skill queue: empty"
+	default:
+		return fmt.Sprintf("Skill queue ends: %s (in %s)", queue.End.Local().Format("2006-01-02 15:04"),
+			time.Until(queue.End).Round(time.Minute))
+	}
+}
+
+// planetText describes a colony's next extractor expiry and storage state.
+func planetText(planet esiwatch.PlanetState) string {
+	text := fmt.Sprintf("%s (%s): ", planet.Name, planet.Type)
+	if planet.NextExpiry.IsZero() {
+		text += "no extractors"
+	} else if time.Now().After(planet.NextExpiry) {
+		text += "extractors stopped"
+	} else {
+		text += "extractors stop " + planet.NextExpiry.Local().Format("2006-01-02 15:04")
+	}
+	if !planet.StorageFull.IsZero() {
+		text += ", storage full " + planet.StorageFull.Local().Format("2006-01-02 15:04")
+	}
+	return text
+}
+
+// newMailLabelsGroup lets the user pick which standard mail labels are watched.
+func newMailLabelsGroup(cfg *config.Service) *widget.CheckGroup {
+	labels := []struct {
+		name string
+		id   int
+	}{
+		{"Inbox", config.MailLabelInbox},
+		{"Corporation", config.MailLabelCorporation},
+		{"Alliance", config.MailLabelAlliance},
+	}
+	var options, selected []string
+	watched := cfg.GetMailLabels()
+	for _, label := range labels {
+		options = append(options, label.name)
+		for _, id := range watched {
+			if id == label.id {
+				selected = append(selected, label.name)
+			}
+		}
+	}
+	group := widget.NewCheckGroup(options, nil)
+	group.Horizontal = true
+	group.SetSelected(selected)
+	group.OnChanged = func(names []string) {
+		var ids []int
+		for _, label := range labels {
+			for _, name := range names {
+				if name == label.name {
+					ids = append(ids, label.id)
+				}
+			}
+		}
+		cfg.SetMailLabels(ids)
+	}
+	return group
+}
+
+// newPlaceFilterEntry builds an entry for a comma separated list of places.
+func newPlaceFilterEntry(value string, onChanged func(string)) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder("Regions, constellations or systems; * for all")
+	entry.SetText(value)
+	entry.OnChanged = onChanged
+	return entry
+}
+
+// newHoldCapacityEntry edits a character's ore hold size. Left at 0, the size
+// is learned from the volume mined when the hold first reports full.
+func newHoldCapacityEntry(miningSvc *mining.Service, charID int64) fyne.CanvasObject {
+	capacity, learned := miningSvc.HoldCapacity(charID)
+	entered := int(capacity)
+	if learned {
+		entered = 0
+	}
+	entry := newWholeNumberEntry(entered, "m³ (0 = learn when full)", "m³", func(m3 int) {
+		miningSvc.SetHoldCapacity(charID, float64(m3))
+	})
+	if learned {
+		return container.NewBorder(nil, nil, nil, widget.NewLabel(fmt.Sprintf("learned %.0f m³", capacity)), entry)
+	}
+	return entry
+}
+
+// newMinutesEntry builds an entry for a whole number of minutes, where 0 means
+// disabled. Valid input is passed to onChanged as the user types.
+func newMinutesEntry(value int, onChanged func(int)) *widget.Entry {
+	return newWholeNumberEntry(value, "Minutes (0 = never)", "minutes", onChanged)
+}
+
+// newWholeNumberEntry builds an entry for a non-negative whole number of unit.
+func newWholeNumberEntry(value int, placeholder, unit string, onChanged func(int)) *widget.Entry {
+	entry := widget.NewEntry()
+	entry.SetPlaceHolder(placeholder)
+	entry.SetText(strconv.Itoa(value))
+	entry.Validator = func(text string) error {
+		if n, err := strconv.Atoi(text); err != nil || n < 0 {
+			return fmt.Errorf("enter a whole number of %s", unit)
+		}
+		return nil
+	}
+	entry.OnChanged = func(text string) {
+		if n, err := strconv.Atoi(text); err == nil && n >= 0 {
+			onChanged(n)
+		}
+	}
+	return entry
+}
+
 func setContainerEnabled(c *fyne.Container, enabled bool) {
 	for _, obj := range c.Objects {
 		if w, ok := obj.(fyne.Disableable); ok {
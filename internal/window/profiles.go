@@ -0,0 +1,123 @@
+package window
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/notification"
+	"github.com/FabricSoul/eve-notify/pkg/profile"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// noProfile is the select option shown when a character has no profile.
+const noProfile = "(none)"
+
+// showProfilesDialog lets the user create, edit and delete settings profiles.
+func showProfilesDialog(window fyne.Window, profileSvc *profile.Service) {
+	settings := &subscription.NotificationSettings{}
+	formHolder := container.NewVBox(newSettingsForm(settings))
+
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("New profile name")
+
+	profileSelect := widget.NewSelect(profileSvc.Profiles(), func(name string) {
+		loaded, ok := profileSvc.GetProfile(name)
+		if !ok {
+			return
+		}
+		settings = loaded
+		nameEntry.SetText(name)
+		formHolder.Objects = []fyne.CanvasObject{newSettingsForm(settings)}
+		formHolder.Refresh()
+	})
+	profileSelect.PlaceHolder = "Select a profile to edit"
+
+	saveButton := widget.NewButton("Save", func() {
+		name := strings.TrimSpace(nameEntry.Text)
+		if name == "" {
+			dialog.ShowError(fmt.Errorf("profile name must not be empty"), window)
+			return
+		}
+		logger.Sugar.Infof("User saved profile '%s'.", name)
+		profileSvc.SaveProfile(name, settings)
+		profileSelect.Options = profileSvc.Profiles()
+		profileSelect.SetSelected(name)
+	})
+	deleteButton := widget.NewButton("Delete", func() {
+		name := profileSelect.Selected
+		if name == "" {
+			return
+		}
+		dialog.ShowConfirm("Delete Profile", fmt.Sprintf("Delete profile '%s'?", name), func(ok bool) {
+			if !ok {
+				return
+			}
+			profileSvc.DeleteProfile(name)
+			profileSelect.Options = profileSvc.Profiles()
+			profileSelect.ClearSelected()
+			nameEntry.SetText("")
+		}, window)
+	})
+
+	content := container.NewVBox(
+		profileSelect,
+		widget.NewForm(widget.NewFormItem("Name", nameEntry)),
+		widget.NewSeparator(),
+		formHolder,
+		container.NewGridWithColumns(2, saveButton, deleteButton),
+	)
+	d := dialog.NewCustom("Profiles", "Close", content, window)
+	d.Resize(fyne.NewSize(480, 480))
+	d.Show()
+}
+
+// showGroupsDialog offers bulk actions on every character tagged with a group.
+// onChange is called after any action that changes subscriptions.
+func showGroupsDialog(window fyne.Window, profileSvc *profile.Service, notifSvc *notification.Service, onChange func()) {
+	groupSelect := widget.NewSelect(profileSvc.Groups(), nil)
+	groupSelect.PlaceHolder = "Select a group"
+	profileSelect := widget.NewSelect(profileSvc.Profiles(), nil)
+	profileSelect.PlaceHolder = "Select a profile"
+
+	applyButton := widget.NewButton("Apply Profile", func() {
+		if groupSelect.Selected == "" || profileSelect.Selected == "" {
+			return
+		}
+		profileSvc.ApplyProfileToGroup(groupSelect.Selected, profileSelect.Selected)
+		onChange()
+	})
+	subscribeButton := widget.NewButton("Subscribe Group", func() {
+		group := groupSelect.Selected
+		if group == "" {
+			return
+		}
+		profileSvc.SubscribeGroup(group)
+		notifSvc.Notify("Subscription Active", fmt.Sprintf("Now monitoring %d characters in group %s.", len(profileSvc.Members(group)), group), false)
+		onChange()
+	})
+	unsubscribeButton := widget.NewButton("Unsubscribe Group", func() {
+		if groupSelect.Selected == "" {
+			return
+		}
+		profileSvc.UnsubscribeGroup(groupSelect.Selected)
+		onChange()
+	})
+
+	content := container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Group", groupSelect),
+			widget.NewFormItem("Profile", profileSelect),
+		),
+		applyButton,
+		widget.NewSeparator(),
+		container.NewGridWithColumns(2, subscribeButton, unsubscribeButton),
+	)
+	d := dialog.NewCustom("Groups", "Close", content, window)
+	d.Resize(fyne.NewSize(420, 260))
+	d.Show()
+}
@@ -0,0 +1,84 @@
+package window
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FabricSoul/eve-notify/pkg/character"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/notification"
+	"github.com/FabricSoul/eve-notify/pkg/sso"
+)
+
+// newAuthorizationSection shows a character's ESI authorization state with a
+// button to add or remove it. onChange is called after either action.
+func newAuthorizationSection(app fyne.App, window fyne.Window, ssoSvc *sso.Service, notifSvc *notification.Service, char *character.Character, onChange func()) fyne.CanvasObject {
+	token, authorized := ssoSvc.Authorization(char.ID)
+	if authorized {
+		status := widget.NewLabel(fmt.Sprintf("ESI authorized (%d scopes)", len(token.Scopes)))
+		removeButton := widget.NewButtonWithIcon("Remove ESI authorization", theme.DeleteIcon(), func() {
+			dialog.ShowConfirm("Remove Authorization", fmt.Sprintf("Remove ESI authorization for %s?", char.Name), func(ok bool) {
+				if ok {
+					ssoSvc.Remove(char.ID)
+					onChange()
+				}
+			}, window)
+		})
+		return container.NewBorder(nil, nil, nil, removeButton, status)
+	}
+
+	addButton := widget.NewButtonWithIcon("Add ESI authorization", theme.LoginIcon(), func() {
+		showAuthorizeDialog(app, window, ssoSvc, notifSvc, char, onChange)
+	})
+	return container.NewBorder(nil, nil, nil, addButton, widget.NewLabel("ESI not authorized"))
+}
+
+// showAuthorizeDialog lets the user pick scopes and then runs the SSO login.
+func showAuthorizeDialog(app fyne.App, window fyne.Window, ssoSvc *sso.Service, notifSvc *notification.Service, char *character.Character, onChange func()) {
+	var options []string
+	byDescription := make(map[string]string)
+	for _, scope := range sso.KnownScopes {
+		options = append(options, scope.Description)
+		byDescription[scope.Description] = scope.Name
+	}
+	scopeGroup := widget.NewCheckGroup(options, nil)
+	scopeGroup.SetSelected(options)
+
+	hint := widget.NewLabel(fmt.Sprintf("Log in as %s in the browser window that opens.\nCallback URL: %s", char.Name, ssoSvc.CallbackURL()))
+	content := container.NewVBox(hint, widget.NewSeparator(), scopeGroup)
+
+	dialog.ShowCustomConfirm("Add ESI Authorization", "Log in", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		var scopes []string
+		for _, description := range scopeGroup.Selected {
+			scopes = append(scopes, byDescription[description])
+		}
+
+		// Login blocks until the browser redirects back, so run it off the UI
+		// thread and hand the result back with fyne.Do.
+		go func() {
+			token, err := ssoSvc.Login(context.Background(), scopes, app.OpenURL)
+			if err != nil {
+				logger.Sugar.Errorf("SSO login failed: %v", err)
+				fyne.Do(func() { dialog.ShowError(err, window) })
+				return
+			}
+			notifSvc.Notify("ESI Authorization", fmt.Sprintf("%s authorized: %s", token.CharacterName, strings.Join(token.Scopes, ", ")), false)
+			fyne.Do(func() {
+				if token.CharacterID != char.ID {
+					dialog.ShowInformation("Different Character",
+						fmt.Sprintf("You logged in as %s, so that character was authorized instead of %s.", token.CharacterName, char.Name), window)
+				}
+				onChange()
+			})
+		}()
+	}, window)
+}
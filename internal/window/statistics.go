@@ -0,0 +1,306 @@
+package window
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/FabricSoul/eve-notify/pkg/bounty"
+	"github.com/FabricSoul/eve-notify/pkg/character"
+	"github.com/FabricSoul/eve-notify/pkg/combat"
+	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/mining"
+)
+
+// miningColumns are the headers of the mining statistics table.
+var miningColumns = []string{"Session", "Duration", "Ore", "Units", "m³", "Units/h", "m³/h", "ISK/h"}
+
+// miningRow is one line of the mining statistics table; totals have no ore.
+type miningRow struct {
+	session  time.Time
+	duration time.Duration
+	ore      string
+	units    int64
+	volume   float64 // -1 if an ore's volume is unknown.
+	isk      float64 // -1 if not valued.
+}
+
+// rattingColumns are the headers of the bounty table.
+var rattingColumns = []string{"Session", "Duration", "Kills", "Ticks", "Bounties", "ESS", "ISK/h", "Best Tick"}
+
+// combatColumns are the headers of the combat table.
+var combatColumns = []string{"Session", "Duration", "DPS Out", "DPS In", "Peak In", "Hits/Misses Out", "Hits/Misses In", "Top Attackers", "Top Weapons", "Hit Quality"}
+
+// NewStatisticsWindow shows mining yield per character, session and ore,
+// ratting bounties and combat damage per session.
+func NewStatisticsWindow(app fyne.App, cfg *config.Service, charSvc *character.Service, miningSvc *mining.Service, bountySvc *bounty.Service, combatSvc *combat.Service) fyne.Window {
+	window := app.NewWindow("EVE Notify - Statistics")
+
+	table := container.NewGridWithColumns(len(miningColumns))
+	rattingTable := container.NewGridWithColumns(len(rattingColumns))
+	combatTable := container.NewGridWithColumns(len(combatColumns))
+	status := widget.NewLabel("Select a character.")
+	charIDs := make(map[string]int64)
+
+	var show func()
+	charSelect := widget.NewSelect(nil, func(string) { show() })
+	charSelect.PlaceHolder = "Select a character"
+	// The saved state is set before OnChanged, which needs show.
+	pricesCheck := widget.NewCheck("Value ore at ESI average prices", nil)
+	pricesCheck.Checked = cfg.GetMiningOrePrices()
+	pricesCheck.OnChanged = func(enabled bool) {
+		cfg.SetMiningOrePrices(enabled)
+		show()
+	}
+
+	// Volumes and prices may need ESI, so rows are built off the UI thread.
+	show = func() {
+		charID, ok := charIDs[charSelect.Selected]
+		if !ok {
+			return
+		}
+		withPrices := pricesCheck.Checked
+		status.SetText("Loading...")
+		go func() {
+			rows := miningRows(context.Background(), miningSvc, miningSvc.Sessions(charID), withPrices)
+			bounties := bountySvc.Sessions(charID)
+			fights := combatSvc.Sessions(charID)
+			fyne.Do(func() {
+				table.Objects = nil
+				for _, column := range miningColumns {
+					table.Add(widget.NewLabelWithStyle(column, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+				}
+				for _, row := range rows {
+					for _, cell := range row.cells() {
+						table.Add(widget.NewLabel(cell))
+					}
+				}
+				table.Refresh()
+
+				rattingTable.Objects = nil
+				for _, column := range rattingColumns {
+					rattingTable.Add(widget.NewLabelWithStyle(column, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+				}
+				for _, session := range bounties {
+					for _, cell := range rattingCells(session) {
+						rattingTable.Add(widget.NewLabel(cell))
+					}
+				}
+				rattingTable.Refresh()
+
+				combatTable.Objects = nil
+				for _, column := range combatColumns {
+					combatTable.Add(widget.NewLabelWithStyle(column, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+				}
+				for _, session := range fights {
+					for _, cell := range combatCells(session) {
+						combatTable.Add(widget.NewLabel(cell))
+					}
+				}
+				combatTable.Refresh()
+				status.SetText(fmt.Sprintf("%d mining, %d ratting and %d combat sessions.", len(miningSvc.Sessions(charID)), len(bounties), len(fights)))
+			})
+		}()
+	}
+
+	exportButton := widget.NewButton("Export CSV", func() {
+		charID, ok := charIDs[charSelect.Selected]
+		if !ok {
+			dialog.ShowInformation("Export", "Select a character first.", window)
+			return
+		}
+		save := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, window)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			defer writer.Close()
+			if err := bountySvc.ExportCSV(charID, writer); err != nil {
+				logger.Sugar.Errorf("Failed to export bounties: %v", err)
+				dialog.ShowError(err, window)
+				return
+			}
+			logger.Sugar.Infof("Exported bounties of %d to %s", charID, writer.URI())
+		}, window)
+		save.SetFileName(fmt.Sprintf("bounties-%s.csv", charSelect.Selected))
+		save.Show()
+	})
+
+	loadCharacters := func() {
+		go func() {
+			chars, err := charSvc.GetCharacters()
+			if err != nil {
+				logger.Sugar.Errorf("Failed to list characters for statistics: %v", err)
+			}
+			names := make(map[int64]string, len(chars))
+			for _, char := range chars {
+				names[char.ID] = char.Name
+			}
+			options := make(map[string]int64)
+			for _, id := range slices.Concat(miningSvc.Characters(), bountySvc.Characters(), combatSvc.Characters()) {
+				name, ok := names[id]
+				if !ok {
+					name = strconv.FormatInt(id, 10)
+				}
+				options[name] = id
+			}
+			fyne.Do(func() {
+				charIDs = options
+				charSelect.Options = charSelect.Options[:0]
+				for name := range options {
+					charSelect.Options = append(charSelect.Options, name)
+				}
+				sort.Strings(charSelect.Options)
+				charSelect.Refresh()
+				show()
+			})
+		}()
+	}
+
+	refreshButton := widget.NewButton("Refresh", loadCharacters)
+	tabs := container.NewAppTabs(
+		container.NewTabItem("Mining", container.NewBorder(pricesCheck, nil, nil, nil, container.NewVScroll(table))),
+		container.NewTabItem("Ratting", container.NewBorder(container.NewHBox(exportButton), nil, nil, nil, container.NewVScroll(rattingTable))),
+		container.NewTabItem("Combat", container.NewVScroll(combatTable)),
+	)
+	top := container.NewBorder(nil, nil, nil, refreshButton, charSelect)
+	window.SetContent(container.NewPadded(container.NewBorder(top, status, nil, nil, tabs)))
+	window.Resize(fyne.NewSize(1100, 600))
+
+	loadCharacters()
+
+	window.SetCloseIntercept(func() {
+		logger.Sugar.Infoln("Statistics window closed by user, hiding to tray.")
+		window.Hide()
+	})
+	return window
+}
+
+// miningRows builds a total row followed by one row per ore for each session.
+func miningRows(ctx context.Context, miningSvc *mining.Service, sessions []mining.Session, withPrices bool) []miningRow {
+	var rows []miningRow
+	for _, session := range sessions {
+		total := miningRow{session: session.Started, duration: session.Duration(), isk: -1}
+		if withPrices {
+			total.isk = 0
+		}
+		var perOre []miningRow
+		for _, name := range session.OreNames() {
+			ore := session.Ores[name]
+			row := miningRow{session: session.Started, duration: session.Duration(), ore: name, units: ore.Units, volume: -1, isk: -1}
+			if volume, ok := miningSvc.Volume(ctx, name); ok {
+				row.volume = volume * float64(ore.Units)
+			}
+			if withPrices {
+				if price, ok := miningSvc.Price(ctx, name); ok {
+					row.isk = price * float64(ore.Units)
+				}
+			}
+
+			total.units += row.units
+			if row.volume >= 0 && total.volume >= 0 {
+				total.volume += row.volume
+			} else {
+				total.volume = -1
+			}
+			if row.isk >= 0 && total.isk >= 0 {
+				total.isk += row.isk
+			} else {
+				total.isk = -1
+			}
+			perOre = append(perOre, row)
+		}
+		rows = append(rows, total)
+		rows = append(rows, perOre...)
+	}
+	return rows
+}
+
+// cells formats a row for the table. Rates need at least a minute of mining
+// to mean anything.
+func (r miningRow) cells() []string {
+	session, duration, ore := "", "", "  "+r.ore
+	if r.ore == "" {
+		session = r.session.Local().Format("2006-01-02 15:04")
+		duration = r.duration.Round(time.Minute).String()
+		ore = "All ores"
+	}
+	hours := r.duration.Hours()
+	perHour := func(amount float64, format string) string {
+		if amount < 0 || r.duration < time.Minute {
+			return "-"
+		}
+		return fmt.Sprintf(format, amount/hours)
+	}
+	volume := "-"
+	if r.volume >= 0 {
+		volume = fmt.Sprintf("%.0f", r.volume)
+	}
+	return []string{
+		session,
+		duration,
+		ore,
+		strconv.FormatInt(r.units, 10),
+		volume,
+		perHour(float64(r.units), "%.0f"),
+		perHour(r.volume, "%.0f"),
+		perHour(r.isk/1e6, "%.1fM"),
+	}
+}
+
+// rattingCells formats a ratting session for the bounty table.
+func rattingCells(session bounty.Session) []string {
+	perHour := "-"
+	if rate := session.PerHour(); rate > 0 {
+		perHour = fmt.Sprintf("%.1fM", rate/1e6)
+	}
+	return []string{
+		session.Started.Local().Format("2006-01-02 15:04"),
+		session.Duration().Round(time.Minute).String(),
+		strconv.Itoa(session.Kills),
+		strconv.Itoa(len(session.Ticks)),
+		fmt.Sprintf("%.1fM", session.Total/1e6),
+		fmt.Sprintf("%.1fM", session.ESS/1e6),
+		perHour,
+		fmt.Sprintf("%.1fM", session.BestTick().Amount/1e6),
+	}
+}
+
+// combatCells formats a combat session for the combat table.
+func combatCells(session combat.Session) []string {
+	var attackers, weapons, qualities []string
+	for _, source := range session.TopSources(3) {
+		attackers = append(attackers, fmt.Sprintf("%s (%d)", source.Name, source.Damage))
+	}
+	for _, weapon := range session.TopWeapons(3) {
+		weapons = append(weapons, fmt.Sprintf("%s (%d)", weapon.Name, weapon.Damage))
+	}
+	for quality, count := range session.Qualities {
+		qualities = append(qualities, fmt.Sprintf("%s %d", quality, count))
+	}
+	sort.Strings(qualities)
+	return []string{
+		session.Started.Local().Format("2006-01-02 15:04"),
+		session.Duration().Round(time.Minute).String(),
+		fmt.Sprintf("%.0f", session.DPSOut()),
+		fmt.Sprintf("%.0f", session.DPSIn()),
+		fmt.Sprintf("%.0f", session.PeakDPSIn()),
+		fmt.Sprintf("%d/%d", session.Dealt.Hits, session.Dealt.Misses),
+		fmt.Sprintf("%d/%d", session.Taken.Hits, session.Taken.Misses),
+		strings.Join(attackers, ", "),
+		strings.Join(weapons, ", "),
+		strings.Join(qualities, ", "),
+	}
+}
@@ -0,0 +1,204 @@
+package bounty
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/jsonfile"
+	"github.com/FabricSoul/eve-notify/pkg/stats"
+)
+
+const (
+	// maxSessions is how many sessions are kept per character.
+	maxSessions = 100
+	// TickLength is how often bounties are paid out. A tick starts with the
+	// first bounty after the previous one ended.
+	TickLength = 20 * time.Minute
+)
+
+// Bounty is one bounty read from a gamelog.
+type Bounty struct {
+	Time   time.Time
+	Amount float64 // ISK added to the character's next payout.
+	ESS    float64 // ISK that went to the Encounter Surveillance System, if logged.
+}
+
+// Tick is the bounties of one payout.
+type Tick struct {
+	Start  time.Time
+	Amount float64
+	Kills  int
+}
+
+// Session totals the bounties of one client session.
+type Session struct {
+	Started  time.Time // When the gamelog was started.
+	First    time.Time // The first bounty.
+	Last     time.Time // The latest bounty.
+	Total    float64
+	ESS      float64
+	Kills    int
+	Ticks    []Tick
+	Finished bool // The summary was sent.
+}
+
+// StartedAt is when the session's gamelog was started.
+func (s *Session) StartedAt() time.Time {
+	return s.Started
+}
+
+// Duration is the time between the first and latest bounty.
+func (s *Session) Duration() time.Duration {
+	return s.Last.Sub(s.First)
+}
+
+// BestTick returns the payout tick with the most ISK.
+func (s *Session) BestTick() Tick {
+	var best Tick
+	for _, tick := range s.Ticks {
+		if tick.Amount > best.Amount {
+			best = tick
+		}
+	}
+	return best
+}
+
+// PerHour is the bounty income per hour, or 0 for sessions under a minute.
+func (s *Session) PerHour() float64 {
+	if d := s.Duration(); d >= time.Minute {
+		return s.Total / d.Hours()
+	}
+	return 0
+}
+
+// Service sums bounties per character, session and payout tick and keeps them
+// in the data directory.
+type Service struct {
+	path     string
+	mu       sync.Mutex
+	sessions map[int64][]*Session // Oldest first.
+}
+
+// NewService loads the bounty history kept in dataDir.
+func NewService(dataDir string) *Service {
+	s := &Service{
+		path:     filepath.Join(dataDir, "bounties.json"),
+		sessions: make(map[int64][]*Session),
+	}
+	jsonfile.Load(s.path, "bounties", &s.sessions)
+	return s
+}
+
+// Record adds a bounty to the session started at started.
+func (s *Service) Record(charID int64, started time.Time, bounty Bounty) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, sessions := stats.Current(s.sessions[charID], started, maxSessions, func() *Session {
+		return &Session{Started: started, First: bounty.Time}
+	})
+	s.sessions[charID] = sessions
+
+	session.Total += bounty.Amount
+	session.ESS += bounty.ESS
+	session.Kills++
+	session.Finished = false
+	if bounty.Time.After(session.Last) {
+		session.Last = bounty.Time
+	}
+	if n := len(session.Ticks); n == 0 || bounty.Time.Sub(session.Ticks[n-1].Start) >= TickLength {
+		session.Ticks = append(session.Ticks, Tick{Start: bounty.Time})
+	}
+	tick := &session.Ticks[len(session.Ticks)-1]
+	tick.Amount += bounty.Amount
+	tick.Kills++
+	s.saveLocked()
+}
+
+// Finish marks the session started at started as over. It returns the session
+// the first time, if it earned any bounties.
+func (s *Service) Finish(charID int64, started time.Time) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := s.sessions[charID]
+	n := len(sessions)
+	if n == 0 || !sessions[n-1].Started.Equal(started) || sessions[n-1].Finished {
+		return Session{}, false
+	}
+	session := sessions[n-1]
+	session.Finished = true
+	s.saveLocked()
+	return session.clone(), true
+}
+
+// Sessions returns copies of a character's sessions, newest first.
+func (s *Service) Sessions(charID int64) []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := s.sessions[charID]
+	copies := make([]Session, 0, len(sessions))
+	for i := len(sessions) - 1; i >= 0; i-- {
+		copies = append(copies, sessions[i].clone())
+	}
+	return copies
+}
+
+// Characters returns the IDs of every character with bounties.
+func (s *Service) Characters() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int64, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// ExportCSV writes one row per session of a character, oldest first.
+func (s *Service) ExportCSV(charID int64, w io.Writer) error {
+	sessions := s.Sessions(charID)
+	out := csv.NewWriter(w)
+	header := []string{"session_started", "first_bounty", "last_bounty", "minutes", "kills", "ticks", "bounty_isk", "ess_isk", "isk_per_hour"}
+	if err := out.Write(header); err != nil {
+		return fmt.Errorf("failed to write bounty report: %w", err)
+	}
+	for i := len(sessions) - 1; i >= 0; i-- {
+		session := sessions[i]
+		record := []string{
+			session.Started.UTC().Format(time.RFC3339),
+			session.First.UTC().Format(time.RFC3339),
+			session.Last.UTC().Format(time.RFC3339),
+			strconv.FormatFloat(session.Duration().Minutes(), 'f', 0, 64),
+			strconv.Itoa(session.Kills),
+			strconv.Itoa(len(session.Ticks)),
+			strconv.FormatFloat(session.Total, 'f', 2, 64),
+			strconv.FormatFloat(session.ESS, 'f', 2, 64),
+			strconv.FormatFloat(session.PerHour(), 'f', 2, 64),
+		}
+		if err := out.Write(record); err != nil {
+			return fmt.Errorf("failed to write bounty report: %w", err)
+		}
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("failed to write bounty report: %w", err)
+	}
+	return nil
+}
+
+func (s *Session) clone() Session {
+	c := *s
+	c.Ticks = append([]Tick(nil), s.Ticks...)
+	return c
+}
+
+func (s *Service) saveLocked() {
+	jsonfile.Save(s.path, "bounties", s.sessions)
+}
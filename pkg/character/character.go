@@ -1,8 +1,8 @@
 package character
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -29,21 +29,60 @@ type Service struct {
 	prefs       fyne.Preferences
 	configSvc *config.Service
 	subSvc    *subscription.Service
+	esiClient *esi.Client
+	index     *Index
 }
 
 // NewService creates a new character service.
-func NewService(app fyne.App, cfg *config.Service, subSvc *subscription.Service) *Service {
+func NewService(app fyne.App, cfg *config.Service, subSvc *subscription.Service, esiClient *esi.Client) *Service {
+	indexPath := ""
+	if dataDir, err := cfg.DataDir(); err == nil {
+		indexPath = filepath.Join(dataDir, "log_index.json")
+	} else {
+		logger.Sugar.Errorf("Log index will not be persisted: %v", err)
+	}
 	return &Service{
 		prefs:       app.Preferences(),
 		configSvc: cfg,
 		subSvc: subSvc,
+		esiClient: esiClient,
+		index:     LoadIndex(indexPath),
 	}
 }
 
+// Index returns the shared log file index.
+func (s *Service) Index() *Index {
+	return s.index
+}
+
+// Sessions returns the gamelog sessions of a character started within the
+// given duration, newest first.
+func (s *Service) Sessions(id int64, within time.Duration) []LogFile {
+	return s.index.Sessions(id, time.Now().Add(-within))
+}
+
 // logFileRegex matches EVE log files that contain a character ID.
 // It captures the timestamp and the character ID.
 var logFileRegex = regexp.MustCompile(`^(\d{8}_\d{6})_(\d+)\.txt$`)
 
+// ParseLogFileName extracts the character ID and session start time from a
+// gamelog file name such as "20240501_120000_12345678.txt".
+func ParseLogFileName(name string) (charID int64, started time.Time, ok bool) {
+	matches := logFileRegex.FindStringSubmatch(name)
+	if len(matches) != 3 {
+		return 0, time.Time{}, false
+	}
+	started, err := time.Parse("20060102_150405", matches[1])
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	charID, err = strconv.ParseInt(matches[2], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return charID, started, true
+}
+
 // GetCharacters discovers characters from the log files.
 func (s *Service) GetCharacters() ([]*Character, error) {
 	logPath := s.configSvc.GetLogPath()
@@ -51,38 +90,28 @@ func (s *Service) GetCharacters() ([]*Character, error) {
 		return nil, fmt.Errorf("EVE log path is not configured")
 	}
 
-	gamelogsPath := filepath.Join(logPath, "Gamelogs")
-	logger.Sugar.Infof("Scanning for characters in: %s", gamelogsPath)
-
-	files, err := os.ReadDir(gamelogsPath)
-	if err != nil {
-		return nil, fmt.Errorf("could not read Gamelogs directory: %w", err)
-	}
-
-	charLatestTime := make(map[int64]time.Time)
-	for _, file := range files {
-		if file.IsDir() { continue }
-		matches := logFileRegex.FindStringSubmatch(file.Name())
-		if len(matches) != 3 { continue }
-		timestampStr, charIDStr := matches[1], matches[2]
-		charID, _ := strconv.ParseInt(charIDStr, 10, 64)
-		logTime, err := time.Parse("20060102_150405", timestampStr)
-		if err != nil { continue }
-		if logTime.After(charLatestTime[charID]) {
-			charLatestTime[charID] = logTime
-		}
+	logger.Sugar.Infof("Updating log index for: %s", logPath)
+	if err := s.index.Update(logPath); err != nil {
+		return nil, err
 	}
 
 	var characters []*Character
-	for id, lastSeen := range charLatestTime {
-		name := s.getCharacterName(id)
+	var unresolved []int64
+	for id, latest := range s.index.LatestGamelogs() {
+		name, ok := s.getCharacterName(id, latest.Path(logPath))
+		if !ok {
+			unresolved = append(unresolved, id)
+		}
 		characters = append(characters, &Character{
 			ID:           id,
 			Name:         name,
-			LastSeen:     lastSeen,
+			LastSeen:     latest.Started,
 			IsSubscribed: s.subSvc.IsSubscribed(id),
 		})
 	}
+	if len(unresolved) > 0 {
+		s.resolveNames(characters, unresolved)
+	}
 
 	// Sort by subscription status (true first), then by last seen time (desc).
 	sort.Slice(characters, func(i, j int) bool {
@@ -97,27 +126,54 @@ func (s *Service) GetCharacters() ([]*Character, error) {
 	return characters, nil
 }
 
-// getCharacterName retrieves a character's name, using a cache first.
-func (s *Service) getCharacterName(id int64) string {
+// getCharacterName retrieves a character's name without using the network.
+// The listener name in the header of the character's newest log is preferred,
+// then the cache. If neither is available it returns a placeholder and false.
+func (s *Service) getCharacterName(id int64, latestLog string) (string, bool) {
 	cacheKey := fmt.Sprintf("char_name_%d", id)
-
-	// 1. Check the cache (fyne.Preferences)
 	cachedName := s.prefs.String(cacheKey)
+
+	// 1. Read the name from the newest log header. This works fully offline.
+	if latestLog != "" {
+		header, err := ReadLogHeader(latestLog)
+		if err == nil {
+			s.recordName(id, header.Listener, header.SessionStarted)
+			if header.Listener != cachedName {
+				s.prefs.SetString(cacheKey, header.Listener)
+			}
+			return header.Listener, true
+		}
+		logger.Sugar.Debugf("No usable header for character ID %d: %v", id, err)
+	}
+
+	// 2. Fall back to the cache (fyne.Preferences)
 	if cachedName != "" {
 		logger.Sugar.Debugf("Cache hit for character ID %d: %s", id, cachedName)
-		return cachedName
+		return cachedName, true
 	}
 
-	// 2. If not in cache, fetch from ESI
-	logger.Sugar.Infof("Cache miss for character ID %d. Fetching from ESI.", id)
-	name, err := esi.GetCharacterName(id)
+	// Return a placeholder so the app doesn't break
+	return fmt.Sprintf("Character %d", id), false
+}
+
+// resolveNames asks ESI for the names of all unresolved characters in a single
+// bulk request and fills them in. Failures keep the placeholder names.
+func (s *Service) resolveNames(characters []*Character, unresolved []int64) {
+	logger.Sugar.Infof("Cache miss for %d characters. Fetching from ESI.", len(unresolved))
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	names, err := s.esiClient.ResolveNames(ctx, unresolved)
 	if err != nil {
-		logger.Sugar.Errorf("Failed to get name for character ID %d from ESI: %v", id, err)
-		// Return a placeholder so the app doesn't break
-		return fmt.Sprintf("Character %d", id)
+		logger.Sugar.Errorf("Failed to get character names from ESI: %v", err)
+	}
+	for _, char := range characters {
+		entry, ok := names[char.ID]
+		if !ok {
+			continue
+		}
+		char.Name = entry.Name
+		s.recordName(char.ID, entry.Name, time.Now())
+		s.prefs.SetString(fmt.Sprintf("char_name_%d", char.ID), entry.Name)
 	}
-
-	// 3. Save to cache for next time
-	s.prefs.SetString(cacheKey, name)
-	return name
 }
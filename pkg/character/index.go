@@ -0,0 +1,350 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// LogType tells which EVE log directory a file lives in.
+type LogType string
+
+const (
+	Gamelog LogType = "Gamelogs"
+	Chatlog LogType = "Chatlogs"
+)
+
+// LogFile is one indexed log file.
+type LogFile struct {
+	Type    LogType
+	Name    string
+	CharID  int64
+	Channel string    // Chat channel name; empty for gamelogs.
+	Started time.Time // Session start from the file name.
+	Size    int64
+	ModTime time.Time
+}
+
+// Path returns the full path of the file below the given EVE log directory.
+func (f *LogFile) Path(logPath string) string {
+	return filepath.Join(logPath, string(f.Type), f.Name)
+}
+
+// chatlogFileRegex matches chat logs that carry a character ID, e.g.
+// "Local_20240501_120000_12345678.txt". It captures channel, timestamp and ID.
+var chatlogFileRegex = regexp.MustCompile(`^(.+)_(\d{8}_\d{6})_(\d+)\.txt$`)
+
+// parseChatlogFileName extracts the channel, session start and character ID
+// from a chat log file name.
+func parseChatlogFileName(name string) (channel string, charID int64, started time.Time, ok bool) {
+	matches := chatlogFileRegex.FindStringSubmatch(name)
+	if len(matches) != 4 {
+		return "", 0, time.Time{}, false
+	}
+	started, err := time.Parse("20060102_150405", matches[2])
+	if err != nil {
+		return "", 0, time.Time{}, false
+	}
+	charID, err = strconv.ParseInt(matches[3], 10, 64)
+	if err != nil {
+		return "", 0, time.Time{}, false
+	}
+	return matches[1], charID, started, true
+}
+
+// Index is a persistent, incrementally updated index of EVE log files.
+// A directory is only listed again when its modification time changes, and
+// only files not yet in the index are parsed and stat'ed. It is thread-safe.
+type Index struct {
+	mu       sync.RWMutex
+	savePath string
+
+	// LogPath is the EVE log directory the index was built from.
+	LogPath string
+	// DirModTimes holds the modification time of each directory at the last scan.
+	DirModTimes map[LogType]time.Time
+	// Files maps "<type>/<name>" to the indexed file.
+	Files map[string]*LogFile
+
+	// Lookups kept up to date as files are indexed, and only rebuilt when
+	// files are deleted: the newest gamelog of each character, the newest
+	// file per type, character and channel, each character's gamelogs and
+	// the newest chat log of each channel.
+	latest    map[int64]*LogFile
+	newest    map[newestKey]*LogFile
+	gamelogs  map[int64][]*LogFile
+	channels  map[string]*LogFile
+	listeners []func(LogFile)
+}
+
+// newestKey looks up the newest file of a type and character. Chat logs are
+// kept both per channel and with an empty channel for any channel.
+type newestKey struct {
+	Type    LogType
+	CharID  int64
+	Channel string
+}
+
+// LoadIndex reads an index from disk. A missing or unreadable file yields an
+// empty index that will be saved to the same path.
+func LoadIndex(savePath string) *Index {
+	idx := &Index{savePath: savePath}
+	raw, err := os.ReadFile(savePath)
+	if err == nil {
+		if err := json.Unmarshal(raw, idx); err != nil {
+			logger.Sugar.Warnf("Discarding unreadable log index %s: %v", savePath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		logger.Sugar.Warnf("Could not read log index %s: %v", savePath, err)
+	}
+	if idx.Files == nil || idx.DirModTimes == nil {
+		idx.reset("")
+	}
+	idx.rebuildLocked()
+	logger.Sugar.Infof("Loaded log index with %d files.", len(idx.Files))
+	return idx
+}
+
+// OnNewFile registers a function that is called for every file added to the
+// index. It is called outside the index lock.
+func (idx *Index) OnNewFile(fn func(LogFile)) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.listeners = append(idx.listeners, fn)
+}
+
+// Update brings the index up to date with the given EVE log directory.
+func (idx *Index) Update(logPath string) error {
+	if logPath == "" {
+		return fmt.Errorf("EVE log path is not configured")
+	}
+
+	idx.mu.Lock()
+	if idx.LogPath != logPath {
+		logger.Sugar.Infof("Log path changed, rebuilding log index for: %s", logPath)
+		idx.reset(logPath)
+	}
+
+	var added []LogFile
+	var firstErr error
+	changed := false
+	for _, logType := range []LogType{Gamelog, Chatlog} {
+		newFiles, dirChanged, err := idx.updateDirLocked(logPath, logType)
+		if err != nil && firstErr == nil && logType == Gamelog {
+			firstErr = err
+		}
+		added = append(added, newFiles...)
+		changed = changed || dirChanged
+	}
+	idx.refreshLatestLocked(logPath)
+	if changed {
+		if err := idx.saveLocked(); err != nil {
+			logger.Sugar.Errorf("Failed to save log index: %v", err)
+		}
+	}
+	listeners := append([]func(LogFile){}, idx.listeners...)
+	idx.mu.Unlock()
+
+	if len(added) > 0 {
+		logger.Sugar.Infof("Indexed %d new log files.", len(added))
+	}
+	for _, file := range added {
+		for _, fn := range listeners {
+			fn(file)
+		}
+	}
+	return firstErr
+}
+
+// updateDirLocked scans one log directory if it changed since the last scan.
+// The caller must hold the write lock.
+func (idx *Index) updateDirLocked(logPath string, logType LogType) (added []LogFile, changed bool, err error) {
+	dir := filepath.Join(logPath, string(logType))
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read %s directory: %w", logType, err)
+	}
+	if info.ModTime().Equal(idx.DirModTimes[logType]) {
+		return nil, false, nil
+	}
+
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not open %s directory: %w", logType, err)
+	}
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	if err != nil {
+		return nil, false, fmt.Errorf("could not list %s directory: %w", logType, err)
+	}
+
+	present := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := string(logType) + "/" + name
+		present[key] = true
+		if _, known := idx.Files[key]; known {
+			continue
+		}
+		file := &LogFile{Type: logType, Name: name}
+		var ok bool
+		if logType == Gamelog {
+			file.CharID, file.Started, ok = ParseLogFileName(name)
+		} else {
+			file.Channel, file.CharID, file.Started, ok = parseChatlogFileName(name)
+		}
+		if !ok {
+			continue
+		}
+		if stat, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			file.Size = stat.Size()
+			file.ModTime = stat.ModTime()
+		}
+		idx.Files[key] = file
+		idx.addLocked(file)
+		added = append(added, *file)
+	}
+
+	// Forget files that were deleted from this directory.
+	prefix := string(logType) + "/"
+	removed := false
+	for key := range idx.Files {
+		if strings.HasPrefix(key, prefix) && !present[key] {
+			delete(idx.Files, key)
+			removed = true
+		}
+	}
+	if removed {
+		idx.rebuildLocked()
+	}
+
+	idx.DirModTimes[logType] = info.ModTime()
+	return added, true, nil
+}
+
+// refreshLatestLocked re-stats the newest gamelog of every character, since
+// those are the only files still being written to. This is one stat per
+// character and is not persisted on its own.
+// The caller must hold the write lock.
+func (idx *Index) refreshLatestLocked(logPath string) {
+	for _, file := range idx.latest {
+		if stat, err := os.Stat(file.Path(logPath)); err == nil {
+			file.Size = stat.Size()
+			file.ModTime = stat.ModTime()
+		}
+	}
+}
+
+// addLocked adds an indexed file to the lookups.
+// The caller must hold the write lock.
+func (idx *Index) addLocked(file *LogFile) {
+	if file.Type == Gamelog {
+		setNewer(idx.latest, file.CharID, file)
+		idx.gamelogs[file.CharID] = append(idx.gamelogs[file.CharID], file)
+	}
+	setNewer(idx.newest, newestKey{file.Type, file.CharID, ""}, file)
+	if file.Channel != "" {
+		setNewer(idx.newest, newestKey{file.Type, file.CharID, file.Channel}, file)
+		setNewer(idx.channels, file.Channel, file)
+	}
+}
+
+// rebuildLocked recomputes the lookups from all indexed files.
+// The caller must hold the write lock.
+func (idx *Index) rebuildLocked() {
+	idx.latest = make(map[int64]*LogFile)
+	idx.newest = make(map[newestKey]*LogFile)
+	idx.gamelogs = make(map[int64][]*LogFile)
+	idx.channels = make(map[string]*LogFile)
+	for _, file := range idx.Files {
+		idx.addLocked(file)
+	}
+}
+
+// setNewer stores file under key unless a file started later is there.
+func setNewer[K comparable](files map[K]*LogFile, key K, file *LogFile) {
+	if current, ok := files[key]; !ok || file.Started.After(current.Started) {
+		files[key] = file
+	}
+}
+
+// LatestGamelogs returns a copy of the newest gamelog of every character.
+func (idx *Index) LatestGamelogs() map[int64]LogFile {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	latest := make(map[int64]LogFile, len(idx.latest))
+	for charID, file := range idx.latest {
+		latest[charID] = *file
+	}
+	return latest
+}
+
+// Latest returns the newest file of a type for one character. For chat logs,
+// a non-empty channel restricts the search to that channel.
+func (idx *Index) Latest(logType LogType, charID int64, channel string) (LogFile, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	latest, ok := idx.newest[newestKey{logType, charID, channel}]
+	if !ok {
+		return LogFile{}, false
+	}
+	return *latest, true
+}
+
+// LatestChannel returns the newest chat log of a channel, whichever character
+// it belongs to.
+func (idx *Index) LatestChannel(channel string) (LogFile, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	latest, ok := idx.channels[channel]
+	if !ok {
+		return LogFile{}, false
+	}
+	return *latest, true
+}
+
+// Sessions returns a character's gamelogs started at or after since, newest first.
+func (idx *Index) Sessions(charID int64, since time.Time) []LogFile {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	var sessions []LogFile
+	for _, file := range idx.gamelogs[charID] {
+		if !file.Started.Before(since) {
+			sessions = append(sessions, *file)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Started.After(sessions[j].Started) })
+	return sessions
+}
+
+func (idx *Index) reset(logPath string) {
+	idx.LogPath = logPath
+	idx.DirModTimes = make(map[LogType]time.Time)
+	idx.Files = make(map[string]*LogFile)
+	idx.rebuildLocked()
+}
+
+// saveLocked writes the index to disk atomically.
+// The caller must hold a lock.
+func (idx *Index) saveLocked() error {
+	if idx.savePath == "" {
+		return nil
+	}
+	raw, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("could not encode log index: %w", err)
+	}
+	tmpPath := idx.savePath + ".tmp"
+	if err := os.WriteFile(tmpPath, raw, 0o644); err != nil {
+		return fmt.Errorf("could not write log index: %w", err)
+	}
+	return os.Rename(tmpPath, idx.savePath)
+}
@@ -0,0 +1,126 @@
+package character
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	sync := logger.Init()
+	code := m.Run()
+	sync()
+	os.Exit(code)
+}
+
+// logDir creates the Gamelogs and Chatlogs directories with the given files.
+func logDir(t *testing.T, gamelogs, chatlogs []string) string {
+	root := t.TempDir()
+	for dir, names := range map[LogType][]string{Gamelog: gamelogs, Chatlog: chatlogs} {
+		if err := os.Mkdir(filepath.Join(root, string(dir)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range names {
+			if err := os.WriteFile(filepath.Join(root, string(dir), name), nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return root
+}
+
+// touchDir moves a directory's modification time forward, so the next update
+// lists it again even on file systems with coarse timestamps.
+func touchDir(t *testing.T, dir string, step int) {
+	at := time.Now().Add(time.Duration(step) * time.Minute)
+	if err := os.Chtimes(dir, at, at); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexLatestAndSessions(t *testing.T) {
+	root := logDir(t,
+		[]string{"20240501_120000_1.txt", "20240502_120000_1.txt", "20240501_130000_2.txt"},
+		[]string{"Local_20240501_120000_1.txt", "Local_20240502_120000_1.txt", "Corp_20240503_120000_1.txt"},
+	)
+	idx := LoadIndex("")
+	if err := idx.Update(root); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		logType  LogType
+		charID   int64
+		channel  string
+		wantName string
+	}{
+		{"newest gamelog", Gamelog, 1, "", "20240502_120000_1.txt"},
+		{"other character", Gamelog, 2, "", "20240501_130000_2.txt"},
+		{"newest chat log of a channel", Chatlog, 1, "Local", "Local_20240502_120000_1.txt"},
+		{"newest chat log of any channel", Chatlog, 1, "", "Corp_20240503_120000_1.txt"},
+		{"unknown channel", Chatlog, 1, "Alliance", ""},
+		{"unknown character", Gamelog, 3, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, ok := idx.Latest(tt.logType, tt.charID, tt.channel)
+			if ok != (tt.wantName != "") || file.Name != tt.wantName {
+				t.Errorf("Latest = %q, %t; want %q", file.Name, ok, tt.wantName)
+			}
+		})
+	}
+
+	if file, _ := idx.LatestChannel("Local"); file.Name != "Local_20240502_120000_1.txt" {
+		t.Errorf("LatestChannel(Local) = %q", file.Name)
+	}
+	if _, ok := idx.LatestChannel("Alliance"); ok {
+		t.Error("LatestChannel found a channel without logs")
+	}
+
+	since := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	if sessions := idx.Sessions(1, since); len(sessions) != 1 || sessions[0].Name != "20240502_120000_1.txt" {
+		t.Errorf("Sessions since %s = %v", since, sessions)
+	}
+}
+
+func TestIndexUpdatesLookupsForNewAndDeletedFiles(t *testing.T) {
+	root := logDir(t, []string{"20240501_120000_1.txt"}, nil)
+	gamelogs := filepath.Join(root, string(Gamelog))
+	idx := LoadIndex("")
+	if err := idx.Update(root); err != nil {
+		t.Fatal(err)
+	}
+
+	newer := filepath.Join(gamelogs, "20240502_120000_1.txt")
+	if err := os.WriteFile(newer, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	touchDir(t, gamelogs, 1)
+	if err := idx.Update(root); err != nil {
+		t.Fatal(err)
+	}
+	if file, _ := idx.Latest(Gamelog, 1, ""); file.Name != "20240502_120000_1.txt" {
+		t.Errorf("after adding a file, Latest = %q", file.Name)
+	}
+	if latest := idx.LatestGamelogs(); latest[1].Name != "20240502_120000_1.txt" {
+		t.Errorf("after adding a file, LatestGamelogs = %v", latest)
+	}
+
+	if err := os.Remove(newer); err != nil {
+		t.Fatal(err)
+	}
+	touchDir(t, gamelogs, 2)
+	if err := idx.Update(root); err != nil {
+		t.Fatal(err)
+	}
+	if file, _ := idx.Latest(Gamelog, 1, ""); file.Name != "20240501_120000_1.txt" {
+		t.Errorf("after deleting a file, Latest = %q", file.Name)
+	}
+	if sessions := idx.Sessions(1, time.Time{}); len(sessions) != 1 {
+		t.Errorf("after deleting a file, Sessions = %v", sessions)
+	}
+}
@@ -0,0 +1,55 @@
+package character
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// LogHeader holds the fields of the banner EVE writes at the top of every log file.
+type LogHeader struct {
+	Listener       string
+	SessionStarted time.Time
+}
+
+// headerTimeLayout is the timestamp format used in log headers and log lines.
+const headerTimeLayout = "2006.01.02 15:04:05"
+
+// headerMaxLines bounds how far into a file ReadLogHeader looks.
+const headerMaxLines = 12
+
+// ReadLogHeader parses the header of a gamelog file.
+func ReadLogHeader(path string) (*LogHeader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not open log file: %w", err)
+	}
+	defer file.Close()
+
+	header := &LogHeader{}
+	scanner := bufio.NewScanner(file)
+	for i := 0; i < headerMaxLines && scanner.Scan(); i++ {
+		key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "listener":
+			header.Listener = value
+		case "session started":
+			if t, err := time.Parse(headerTimeLayout, value); err == nil {
+				header.SessionStarted = t
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read log header: %w", err)
+	}
+	if header.Listener == "" {
+		return nil, fmt.Errorf("no listener found in log header of %s", path)
+	}
+	return header, nil
+}
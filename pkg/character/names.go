@@ -0,0 +1,58 @@
+package character
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// NameRecord is one name a character has been seen under.
+type NameRecord struct {
+	Name      string
+	FirstSeen time.Time
+}
+
+// NameHistory returns every name recorded for a character, oldest first.
+func (s *Service) NameHistory(id int64) []NameRecord {
+	raw := s.prefs.String(fmt.Sprintf("char_name_history_%d", id))
+	if raw == "" {
+		return nil
+	}
+	var history []NameRecord
+	if err := json.Unmarshal([]byte(raw), &history); err != nil {
+		logger.Sugar.Errorf("Failed to decode name history for character ID %d: %v", id, err)
+		return nil
+	}
+	return history
+}
+
+// recordName appends a name to the character's history if it differs from the
+// most recent entry. A change of an already known name is checked against ESI
+// when it is reachable; an unreachable ESI does not block the change.
+func (s *Service) recordName(id int64, name string, seen time.Time) {
+	history := s.NameHistory(id)
+	if len(history) > 0 {
+		previous := history[len(history)-1].Name
+		if previous == name {
+			return
+		}
+		logger.Sugar.Infof("Character ID %d changed name from %s to %s.", id, previous, name)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		esiName, err := s.esiClient.GetCharacterName(ctx, id)
+		cancel()
+		if err == nil && esiName != name {
+			logger.Sugar.Warnf("ESI reports name %s for character ID %d, log header says %s.", esiName, id, name)
+		}
+	}
+
+	history = append(history, NameRecord{Name: name, FirstSeen: seen})
+	raw, err := json.Marshal(history)
+	if err != nil {
+		logger.Sugar.Errorf("Failed to encode name history for character ID %d: %v", id, err)
+		return
+	}
+	s.prefs.SetString(fmt.Sprintf("char_name_history_%d", id), string(raw))
+}
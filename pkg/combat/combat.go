@@ -0,0 +1,348 @@
+package combat
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/jsonfile"
+	"github.com/FabricSoul/eve-notify/pkg/stats"
+)
+
+const (
+	// maxSessions is how many sessions are kept per character.
+	maxSessions = 100
+	// maxWindow is how much incoming damage is kept for DPS alerts, unless
+	// the alert averages over longer.
+	maxWindow = time.Minute
+	// saveInterval limits how often the history is written during a fight.
+	saveInterval = 30 * time.Second
+)
+
+// Hit is one combat line read from a gamelog. Misses have no damage.
+type Hit struct {
+	Time     time.Time
+	Incoming bool // Damage taken rather than dealt.
+	Damage   int64
+	Quality  string // e.g. "Wrecks" or "Glances Off"; empty for misses.
+	Weapon   string // Empty if the client did not log it.
+	Other    string // The target of outgoing hits, the attacker of incoming ones.
+	Miss     bool
+}
+
+// Tally totals the damage of a side, attacker or weapon.
+type Tally struct {
+	Damage int64
+	Hits   int
+	Misses int
+}
+
+func (t *Tally) add(hit Hit) {
+	if hit.Miss {
+		t.Misses++
+		return
+	}
+	t.Damage += hit.Damage
+	t.Hits++
+}
+
+// Minute is the damage of one minute of a session, the time series the rates
+// are drawn from.
+type Minute struct {
+	Start time.Time
+	Dealt int64
+	Taken int64
+}
+
+// Named is a tally with the attacker or weapon it belongs to.
+type Named struct {
+	Name string
+	Tally
+}
+
+// Session totals the combat of one client session.
+type Session struct {
+	Started   time.Time // When the gamelog was started.
+	First     time.Time // The first combat line.
+	Last      time.Time // The latest combat line.
+	Dealt     Tally
+	Taken     Tally
+	Qualities map[string]int    // Outgoing hits by quality.
+	Sources   map[string]*Tally // Incoming damage by attacker.
+	Weapons   map[string]*Tally // Outgoing damage by weapon.
+	Minutes   []Minute          // Minutes without combat are left out.
+}
+
+// StartedAt is when the session's gamelog was started.
+func (s *Session) StartedAt() time.Time {
+	return s.Started
+}
+
+// Duration is the time between the first and latest combat line.
+func (s *Session) Duration() time.Duration {
+	return s.Last.Sub(s.First)
+}
+
+// DPSOut is the average damage dealt per second of combat.
+func (s *Session) DPSOut() float64 {
+	return float64(s.Dealt.Damage) / s.combatSeconds()
+}
+
+// DPSIn is the average damage taken per second of combat.
+func (s *Session) DPSIn() float64 {
+	return float64(s.Taken.Damage) / s.combatSeconds()
+}
+
+// PeakDPSIn is the damage taken per second in the worst minute.
+func (s *Session) PeakDPSIn() float64 {
+	var peak int64
+	for _, minute := range s.Minutes {
+		peak = max(peak, minute.Taken)
+	}
+	return float64(peak) / time.Minute.Seconds()
+}
+
+// TopSources returns up to n attackers that dealt the most damage.
+func (s *Session) TopSources(n int) []Named {
+	return top(s.Sources, n)
+}
+
+// TopWeapons returns up to n weapons that dealt the most damage.
+func (s *Session) TopWeapons(n int) []Named {
+	return top(s.Weapons, n)
+}
+
+// combatSeconds counts the minutes with combat, so breaks between fights do
+// not dilute the rates. The current minute counts as far as it went.
+func (s *Session) combatSeconds() float64 {
+	if len(s.Minutes) == 0 {
+		return 1
+	}
+	full := time.Duration(len(s.Minutes)-1) * time.Minute
+	last := s.Last.Sub(s.Minutes[len(s.Minutes)-1].Start) + time.Second
+	return (full + last).Seconds()
+}
+
+func top(tallies map[string]*Tally, n int) []Named {
+	named := make([]Named, 0, len(tallies))
+	for name, tally := range tallies {
+		named = append(named, Named{Name: name, Tally: *tally})
+	}
+	sort.Slice(named, func(i, j int) bool {
+		if named[i].Damage != named[j].Damage {
+			return named[i].Damage > named[j].Damage
+		}
+		return named[i].Name < named[j].Name
+	})
+	if len(named) > n {
+		named = named[:n]
+	}
+	return named
+}
+
+func (s *Session) clone() Session {
+	c := *s
+	c.Qualities = make(map[string]int, len(s.Qualities))
+	for quality, count := range s.Qualities {
+		c.Qualities[quality] = count
+	}
+	c.Sources = cloneTallies(s.Sources)
+	c.Weapons = cloneTallies(s.Weapons)
+	c.Minutes = append([]Minute(nil), s.Minutes...)
+	return c
+}
+
+func cloneTallies(tallies map[string]*Tally) map[string]*Tally {
+	c := make(map[string]*Tally, len(tallies))
+	for name, tally := range tallies {
+		copied := *tally
+		c[name] = &copied
+	}
+	return c
+}
+
+// incoming is the recent damage taken by a character.
+type incoming struct {
+	hits     []Hit
+	window   time.Duration // How far back hits are kept.
+	notified bool
+}
+
+// prune drops hits that fell out of the window before at.
+func (r *incoming) prune(at time.Time) {
+	keep := r.hits[:0]
+	for _, hit := range r.hits {
+		if at.Sub(hit.Time) < r.window {
+			keep = append(keep, hit)
+		}
+	}
+	r.hits = keep
+}
+
+// Service collects combat per character and session, keeps it in the data
+// directory, and watches recent incoming damage.
+type Service struct {
+	path string
+
+	mu       sync.Mutex
+	sessions map[int64][]*Session // Oldest first.
+	incoming map[int64]*incoming
+	savedAt  time.Time
+	dirty    bool
+}
+
+// NewService loads the combat history kept in dataDir.
+func NewService(dataDir string) *Service {
+	s := &Service{
+		path:     filepath.Join(dataDir, "combat.json"),
+		sessions: make(map[int64][]*Session),
+		incoming: make(map[int64]*incoming),
+	}
+	jsonfile.Load(s.path, "combat statistics", &s.sessions)
+	return s
+}
+
+// Record adds a combat line to the session started at started.
+func (s *Service) Record(charID int64, started time.Time, hit Hit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, sessions := stats.Current(s.sessions[charID], started, maxSessions, func() *Session {
+		return &Session{
+			Started:   started,
+			First:     hit.Time,
+			Qualities: make(map[string]int),
+			Sources:   make(map[string]*Tally),
+			Weapons:   make(map[string]*Tally),
+		}
+	})
+	s.sessions[charID] = sessions
+	if hit.Time.After(session.Last) {
+		session.Last = hit.Time
+	}
+
+	minute := hit.Time.Truncate(time.Minute)
+	if n := len(session.Minutes); n == 0 || session.Minutes[n-1].Start.Before(minute) {
+		session.Minutes = append(session.Minutes, Minute{Start: minute})
+	}
+	current := &session.Minutes[len(session.Minutes)-1]
+
+	if hit.Incoming {
+		session.Taken.add(hit)
+		tallyFor(session.Sources, hit.Other).add(hit)
+		if !hit.Miss {
+			current.Taken += hit.Damage
+			// Pruned here as well, as CheckIncoming is not called while
+			// the alert is off.
+			recent := s.incomingLocked(charID)
+			recent.hits = append(recent.hits, hit)
+			recent.prune(hit.Time)
+		}
+	} else {
+		session.Dealt.add(hit)
+		if hit.Weapon != "" {
+			tallyFor(session.Weapons, hit.Weapon).add(hit)
+		}
+		if !hit.Miss {
+			current.Dealt += hit.Damage
+			if hit.Quality != "" {
+				session.Qualities[hit.Quality]++
+			}
+		}
+	}
+
+	s.dirty = true
+	if time.Since(s.savedAt) >= saveInterval {
+		s.saveLocked()
+	}
+}
+
+// CheckIncoming returns the damage taken per second over the period before
+// at. It reports an alert once when that reaches threshold, and again only
+// after it has dropped below.
+func (s *Service) CheckIncoming(charID int64, at time.Time, over time.Duration, threshold float64) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recent := s.incomingLocked(charID)
+	recent.window = max(maxWindow, over)
+	recent.prune(at)
+	var damage int64
+	for _, hit := range recent.hits {
+		if at.Sub(hit.Time) < over {
+			damage += hit.Damage
+		}
+	}
+
+	dps := float64(damage) / over.Seconds()
+	if dps < threshold {
+		recent.notified = false
+		return dps, false
+	}
+	if recent.notified {
+		return dps, false
+	}
+	recent.notified = true
+	return dps, true
+}
+
+// Sessions returns copies of a character's sessions, newest first.
+func (s *Service) Sessions(charID int64) []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := s.sessions[charID]
+	copies := make([]Session, 0, len(sessions))
+	for i := len(sessions) - 1; i >= 0; i-- {
+		copies = append(copies, sessions[i].clone())
+	}
+	return copies
+}
+
+// Characters returns the IDs of every character with combat statistics.
+func (s *Service) Characters() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int64, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Save writes combat recorded since the last save, e.g. when the app quits.
+func (s *Service) Save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dirty {
+		s.saveLocked()
+	}
+}
+
+func (s *Service) incomingLocked(charID int64) *incoming {
+	recent, ok := s.incoming[charID]
+	if !ok {
+		recent = &incoming{window: maxWindow}
+		s.incoming[charID] = recent
+	}
+	return recent
+}
+
+func tallyFor(tallies map[string]*Tally, name string) *Tally {
+	if name == "" {
+		name = "Unknown"
+	}
+	tally, ok := tallies[name]
+	if !ok {
+		tally = &Tally{}
+		tallies[name] = tally
+	}
+	return tally
+}
+
+func (s *Service) saveLocked() {
+	s.savedAt = time.Now()
+	s.dirty = false
+	jsonfile.Save(s.path, "combat statistics", s.sessions)
+}
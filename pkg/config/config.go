@@ -2,6 +2,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -12,7 +13,54 @@ import (
 
 // Keys for storing preferences. Using constants prevents typos.
 const (
-	keyLogPath = "eve_log_path"
+	keyLogPath                = "eve_log_path"
+	keyAutoSubscribe          = "auto_subscribe_enabled"
+	keyAutoSubscribeProfile   = "auto_subscribe_profile"
+	keyAutoUnsubscribeMinutes = "auto_unsubscribe_idle_minutes"
+	keyClientIdleMinutes      = "client_idle_minutes"
+	keyESIBaseURL             = "esi_base_url"
+	keyESIUserAgent           = "esi_user_agent"
+	keySSOClientID            = "sso_client_id"
+	keySSOBaseURL             = "sso_base_url"
+	keySSOCallbackPort        = "sso_callback_port"
+	keySkillQueueWarnMinutes  = "skill_queue_warn_minutes"
+	keyPlanetWarnMinutes      = "planet_warn_minutes"
+	keyMarketFillStepPercent  = "market_fill_step_percent"
+	keyMailLabels             = "mail_labels"
+	keyWalletThreshold        = "wallet_threshold_millions"
+	keyServerStatusAlerts     = "server_status_alerts"
+	keyIncursionFilter        = "universe_incursion_filter"
+	keySovCampaignFilter      = "universe_sov_campaign_filter"
+	keyFWFilter               = "universe_fw_filter"
+	keyMessageTemplate        = "message_template"
+	keyIntelChannels          = "intel_channels"
+	keyIntelRangeJumps        = "intel_range_jumps"
+	keyMiningOrePrices        = "mining_ore_prices"
+	keyCargoWarnMinutes       = "cargo_warn_minutes"
+	keyCombatDPSThreshold     = "combat_dps_threshold"
+	keyCombatDPSSeconds       = "combat_dps_seconds"
+)
+
+// Standard EVE mail label IDs.
+const (
+	MailLabelInbox       = 1
+	MailLabelCorporation = 4
+	MailLabelAlliance    = 8
+)
+
+// Defaults used until the user changes the matching preference.
+const (
+	defaultClientIdleMinutes  = 30
+	defaultSSOBaseURL         = "https://login.eveonline.com"
+	defaultSSOCallbackPort    = 8462
+	defaultSkillQueueWarn     = 24 * 60
+	defaultPlanetWarn         = 60
+	defaultMarketFillStep     = 25
+	defaultWalletThreshold    = 100
+	defaultIntelRangeJumps    = 5
+	defaultCargoWarnMinutes   = 2
+	defaultCombatDPSThreshold = 300
+	defaultCombatDPSSeconds   = 10
 )
 
 // Service provides a structured way to interact with app preferences.
@@ -63,6 +111,319 @@ func (s *Service) SetLogPath(path string) {
 	logger.Sugar.Infof("Set EVE log path to: %s", path)
 }
 
+// GetAutoSubscribe reports whether characters are subscribed automatically
+// when a new gamelog appears.
+func (s *Service) GetAutoSubscribe() bool {
+	return s.prefs.Bool(keyAutoSubscribe)
+}
+
+// SetAutoSubscribe enables or disables automatic subscription.
+func (s *Service) SetAutoSubscribe(enabled bool) {
+	s.prefs.SetBool(keyAutoSubscribe, enabled)
+	logger.Sugar.Infof("Set auto-subscribe to: %t", enabled)
+}
+
+// GetAutoSubscribeProfile returns the profile used for auto-subscribed
+// characters that have no profile of their own.
+func (s *Service) GetAutoSubscribeProfile() string {
+	return s.prefs.String(keyAutoSubscribeProfile)
+}
+
+// SetAutoSubscribeProfile saves the default auto-subscribe profile.
+func (s *Service) SetAutoSubscribeProfile(name string) {
+	s.prefs.SetString(keyAutoSubscribeProfile, name)
+	logger.Sugar.Infof("Set auto-subscribe profile to: %s", name)
+}
+
+// GetAutoUnsubscribeMinutes returns how long a gamelog may stay idle before its
+// auto-subscribed character is unsubscribed. Zero disables auto-unsubscribe.
+func (s *Service) GetAutoUnsubscribeMinutes() int {
+	return s.prefs.Int(keyAutoUnsubscribeMinutes)
+}
+
+// SetAutoUnsubscribeMinutes saves the auto-unsubscribe idle threshold.
+func (s *Service) SetAutoUnsubscribeMinutes(minutes int) {
+	s.prefs.SetInt(keyAutoUnsubscribeMinutes, minutes)
+	logger.Sugar.Infof("Set auto-unsubscribe idle threshold to: %d minutes", minutes)
+}
+
+// GetClientIdleMinutes returns how long a gamelog may stay silent before the
+// client is reported idle. Zero disables idle detection.
+func (s *Service) GetClientIdleMinutes() int {
+	return s.prefs.IntWithFallback(keyClientIdleMinutes, defaultClientIdleMinutes)
+}
+
+// SetClientIdleMinutes saves the client idle threshold.
+func (s *Service) SetClientIdleMinutes(minutes int) {
+	s.prefs.SetInt(keyClientIdleMinutes, minutes)
+	logger.Sugar.Infof("Set client idle threshold to: %d minutes", minutes)
+}
+
+// GetSkillQueueWarnMinutes returns how long before a skill queue runs empty
+// the user is warned. 0 disables the warning.
+func (s *Service) GetSkillQueueWarnMinutes() int {
+	return s.prefs.IntWithFallback(keySkillQueueWarnMinutes, defaultSkillQueueWarn)
+}
+
+// SetSkillQueueWarnMinutes saves the skill queue warning horizon.
+func (s *Service) SetSkillQueueWarnMinutes(minutes int) {
+	s.prefs.SetInt(keySkillQueueWarnMinutes, minutes)
+	logger.Sugar.Infof("Set skill queue warning to: %d minutes", minutes)
+}
+
+// GetPlanetWarnMinutes returns how long before an extractor stops or planet
+// storage fills the user is warned. 0 only reports extractors once they stop.
+func (s *Service) GetPlanetWarnMinutes() int {
+	return s.prefs.IntWithFallback(keyPlanetWarnMinutes, defaultPlanetWarn)
+}
+
+// SetPlanetWarnMinutes saves the planetary interaction warning horizon.
+func (s *Service) SetPlanetWarnMinutes(minutes int) {
+	s.prefs.SetInt(keyPlanetWarnMinutes, minutes)
+	logger.Sugar.Infof("Set planet warning to: %d minutes", minutes)
+}
+
+// GetMarketFillStepPercent returns how much of a market order must fill
+// between partial fill alerts. 0 disables them.
+func (s *Service) GetMarketFillStepPercent() int {
+	return s.prefs.IntWithFallback(keyMarketFillStepPercent, defaultMarketFillStep)
+}
+
+// SetMarketFillStepPercent saves the partial fill alert step.
+func (s *Service) SetMarketFillStepPercent(percent int) {
+	s.prefs.SetInt(keyMarketFillStepPercent, percent)
+	logger.Sugar.Infof("Set market fill step to: %d%%", percent)
+}
+
+// GetMailLabels returns the mail label IDs that new mail alerts watch.
+func (s *Service) GetMailLabels() []int {
+	return s.prefs.IntListWithFallback(keyMailLabels, []int{MailLabelInbox})
+}
+
+// SetMailLabels saves the mail label IDs that new mail alerts watch.
+func (s *Service) SetMailLabels(labels []int) {
+	s.prefs.SetIntList(keyMailLabels, labels)
+	logger.Sugar.Infof("Set mail labels to: %v", labels)
+}
+
+// GetWalletThresholdMillions returns the smallest income, in millions of ISK,
+// that raises a wallet alert.
+func (s *Service) GetWalletThresholdMillions() int {
+	return s.prefs.IntWithFallback(keyWalletThreshold, defaultWalletThreshold)
+}
+
+// SetWalletThresholdMillions saves the wallet alert threshold.
+func (s *Service) SetWalletThresholdMillions(millions int) {
+	s.prefs.SetInt(keyWalletThreshold, millions)
+	logger.Sugar.Infof("Set wallet alert threshold to: %d million ISK", millions)
+}
+
+// GetServerStatusAlerts returns whether Tranquility status changes are notified.
+func (s *Service) GetServerStatusAlerts() bool {
+	return s.prefs.Bool(keyServerStatusAlerts)
+}
+
+// SetServerStatusAlerts enables or disables server status notifications.
+func (s *Service) SetServerStatusAlerts(enabled bool) {
+	s.prefs.SetBool(keyServerStatusAlerts, enabled)
+	logger.Sugar.Infof("Set server status alerts to: %t", enabled)
+}
+
+// GetIncursionFilter returns the comma separated regions, constellations or
+// systems where new incursions are reported. Empty disables the alert.
+func (s *Service) GetIncursionFilter() string {
+	return s.prefs.String(keyIncursionFilter)
+}
+
+// SetIncursionFilter saves the incursion filter.
+func (s *Service) SetIncursionFilter(filter string) {
+	s.prefs.SetString(keyIncursionFilter, filter)
+	logger.Sugar.Infof("Set incursion filter to: %s", filter)
+}
+
+// GetSovCampaignFilter returns the places where starting sovereignty
+// campaigns are reported. Empty disables the alert.
+func (s *Service) GetSovCampaignFilter() string {
+	return s.prefs.String(keySovCampaignFilter)
+}
+
+// SetSovCampaignFilter saves the sovereignty campaign filter.
+func (s *Service) SetSovCampaignFilter(filter string) {
+	s.prefs.SetString(keySovCampaignFilter, filter)
+	logger.Sugar.Infof("Set sovereignty campaign filter to: %s", filter)
+}
+
+// GetFWFilter returns the places where faction warfare contest changes are
+// reported. Empty disables the alert.
+func (s *Service) GetFWFilter() string {
+	return s.prefs.String(keyFWFilter)
+}
+
+// SetFWFilter saves the faction warfare filter.
+func (s *Service) SetFWFilter(filter string) {
+	s.prefs.SetString(keyFWFilter, filter)
+	logger.Sugar.Infof("Set faction warfare filter to: %s", filter)
+}
+
+// GetMessageTemplate returns the template for log based character alerts, or
+// "" for the default.
+func (s *Service) GetMessageTemplate() string {
+	return s.prefs.String(keyMessageTemplate)
+}
+
+// SetMessageTemplate saves the template for log based character alerts.
+func (s *Service) SetMessageTemplate(template string) {
+	s.prefs.SetString(keyMessageTemplate, template)
+	logger.Sugar.Infof("Set message template to: %s", template)
+}
+
+// GetIntelChannels returns the comma separated chat channels read for intel
+// reports. Empty disables intel alerts.
+func (s *Service) GetIntelChannels() string {
+	return s.prefs.String(keyIntelChannels)
+}
+
+// SetIntelChannels saves the intel channels.
+func (s *Service) SetIntelChannels(channels string) {
+	s.prefs.SetString(keyIntelChannels, channels)
+	logger.Sugar.Infof("Set intel channels to: %s", channels)
+}
+
+// GetIntelRangeJumps returns how many jumps from a character a hostile report
+// may be and still raise an alert.
+func (s *Service) GetIntelRangeJumps() int {
+	return s.prefs.IntWithFallback(keyIntelRangeJumps, defaultIntelRangeJumps)
+}
+
+// SetIntelRangeJumps saves the intel alert range.
+func (s *Service) SetIntelRangeJumps(jumps int) {
+	s.prefs.SetInt(keyIntelRangeJumps, jumps)
+	logger.Sugar.Infof("Set intel range to: %d jumps", jumps)
+}
+
+// GetMiningOrePrices returns whether mining statistics value ore at ESI
+// average market prices.
+func (s *Service) GetMiningOrePrices() bool {
+	return s.prefs.Bool(keyMiningOrePrices)
+}
+
+// SetMiningOrePrices enables or disables valuing mined ore.
+func (s *Service) SetMiningOrePrices(enabled bool) {
+	s.prefs.SetBool(keyMiningOrePrices, enabled)
+	logger.Sugar.Infof("Set mining ore prices to: %t", enabled)
+}
+
+// GetCargoWarnMinutes returns how long before the ore hold is expected to
+// fill a warning is sent. 0 disables the warning.
+func (s *Service) GetCargoWarnMinutes() int {
+	return s.prefs.IntWithFallback(keyCargoWarnMinutes, defaultCargoWarnMinutes)
+}
+
+// SetCargoWarnMinutes saves the ore hold warning time.
+func (s *Service) SetCargoWarnMinutes(minutes int) {
+	s.prefs.SetInt(keyCargoWarnMinutes, minutes)
+	logger.Sugar.Infof("Set ore hold warning to: %d minutes", minutes)
+}
+
+// GetCombatDPSThreshold returns the incoming damage per second that raises a
+// combat alert. 0 disables the alert.
+func (s *Service) GetCombatDPSThreshold() int {
+	return s.prefs.IntWithFallback(keyCombatDPSThreshold, defaultCombatDPSThreshold)
+}
+
+// SetCombatDPSThreshold saves the incoming DPS alert threshold.
+func (s *Service) SetCombatDPSThreshold(dps int) {
+	s.prefs.SetInt(keyCombatDPSThreshold, dps)
+	logger.Sugar.Infof("Set incoming DPS alert to: %d", dps)
+}
+
+// GetCombatDPSSeconds returns over how many seconds incoming damage is
+// averaged for the combat alert.
+func (s *Service) GetCombatDPSSeconds() int {
+	return s.prefs.IntWithFallback(keyCombatDPSSeconds, defaultCombatDPSSeconds)
+}
+
+// SetCombatDPSSeconds saves the incoming DPS averaging period.
+func (s *Service) SetCombatDPSSeconds(seconds int) {
+	s.prefs.SetInt(keyCombatDPSSeconds, seconds)
+	logger.Sugar.Infof("Set incoming DPS period to: %d seconds", seconds)
+}
+
+// GetESIBaseURL returns the ESI base URL override, or "" for the default.
+func (s *Service) GetESIBaseURL() string {
+	return s.prefs.String(keyESIBaseURL)
+}
+
+// SetESIBaseURL saves an ESI base URL override. An empty URL restores the default.
+func (s *Service) SetESIBaseURL(url string) {
+	s.prefs.SetString(keyESIBaseURL, url)
+	logger.Sugar.Infof("Set ESI base URL to: %s", url)
+}
+
+// GetESIUserAgent returns the User-Agent override for ESI, or "" for the default.
+func (s *Service) GetESIUserAgent() string {
+	return s.prefs.String(keyESIUserAgent)
+}
+
+// SetESIUserAgent saves a User-Agent override. An empty value restores the default.
+func (s *Service) SetESIUserAgent(userAgent string) {
+	s.prefs.SetString(keyESIUserAgent, userAgent)
+	logger.Sugar.Infof("Set ESI User-Agent to: %s", userAgent)
+}
+
+// GetSSOClientID returns the client ID of the user's EVE SSO application.
+func (s *Service) GetSSOClientID() string {
+	return s.prefs.String(keySSOClientID)
+}
+
+// SetSSOClientID saves the EVE SSO application client ID.
+func (s *Service) SetSSOClientID(clientID string) {
+	s.prefs.SetString(keySSOClientID, clientID)
+	logger.Sugar.Infof("Set SSO client ID to: %s", clientID)
+}
+
+// GetSSOBaseURL returns the EVE SSO base URL.
+func (s *Service) GetSSOBaseURL() string {
+	return s.prefs.StringWithFallback(keySSOBaseURL, defaultSSOBaseURL)
+}
+
+// SetSSOBaseURL saves the EVE SSO base URL, e.g. to point at a mock server.
+func (s *Service) SetSSOBaseURL(url string) {
+	s.prefs.SetString(keySSOBaseURL, url)
+	logger.Sugar.Infof("Set SSO base URL to: %s", url)
+}
+
+// GetSSOCallbackPort returns the loopback port the SSO redirect is sent to.
+func (s *Service) GetSSOCallbackPort() int {
+	return s.prefs.IntWithFallback(keySSOCallbackPort, defaultSSOCallbackPort)
+}
+
+// SetSSOCallbackPort saves the SSO loopback port.
+func (s *Service) SetSSOCallbackPort(port int) {
+	s.prefs.SetInt(keySSOCallbackPort, port)
+	logger.Sugar.Infof("Set SSO callback port to: %d", port)
+}
+
+// DataDir returns the directory where eve-notify keeps its own data files,
+// creating it if necessary.
+func (s *Service) DataDir() (string, error) {
+	return DataDir()
+}
+
+// DataDir returns the directory for eve-notify's own data files, creating it
+// if needed. It does not need a Service, so command line tools can use it.
+func DataDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user config directory: %w", err)
+	}
+	dataDir := filepath.Join(configDir, "eve-notify")
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return "", fmt.Errorf("could not create data directory: %w", err)
+	}
+	return dataDir, nil
+}
+
 // findDefaultEveLogPath tries to find the default EVE Online log directory.
 func (s *Service) findDefaultEveLogPath() string {
 	homeDir, err := os.UserHomeDir()
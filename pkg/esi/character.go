@@ -1,44 +1,60 @@
 package esi
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
-	"time"
-
-	"github.com/FabricSoul/eve-notify/pkg/logger"
 )
 
-// ESI API endpoint for character details
-const characterEndpoint = "https://esi.evetech.net/latest/characters/%d/?datasource=tranquility"
-
-// Client is a basic HTTP client for ESI.
-var client = &http.Client{Timeout: 10 * time.Second}
+// namesChunkSize is the maximum number of IDs /universe/names/ accepts at once.
+const namesChunkSize = 1000
 
 // CharacterResponse models the part of the ESI response we care about.
 type CharacterResponse struct {
-	Name string `json:"name"`
+	Name          string `json:"name"`
+	CorporationID int64  `json:"corporation_id"`
 }
 
-// GetCharacterName fetches a character's name from the ESI API using their ID.
-func GetCharacterName(id int64) (string, error) {
-	url := fmt.Sprintf(characterEndpoint, id)
-	logger.Sugar.Debugf("Fetching character name from ESI: %s", url)
-
-	resp, err := client.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("failed to make request to ESI: %w", err)
-	}
-	defer resp.Body.Close()
+// EntityName is one entry of a /universe/names/ response.
+type EntityName struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("ESI returned non-200 status: %s", resp.Status)
+// GetCharacterName fetches a character's name from the ESI API using their ID.
+func (c *Client) GetCharacterName(ctx context.Context, id int64) (string, error) {
+	var charResp CharacterResponse
+	if _, err := c.Get(ctx, fmt.Sprintf("/characters/%d/", id), &charResp); err != nil {
+		return "", err
 	}
+	return charResp.Name, nil
+}
 
+// GetCharacterCorporationID fetches the ID of a character's current corporation.
+func (c *Client) GetCharacterCorporationID(ctx context.Context, id int64) (int64, error) {
 	var charResp CharacterResponse
-	if err := json.NewDecoder(resp.Body).Decode(&charResp); err != nil {
-		return "", fmt.Errorf("failed to decode ESI response: %w", err)
+	if _, err := c.Get(ctx, fmt.Sprintf("/characters/%d/", id), &charResp); err != nil {
+		return 0, err
 	}
+	return charResp.CorporationID, nil
+}
 
-	return charResp.Name, nil
+// ResolveNames resolves many IDs of any kind (characters, corporations, types,
+// systems, ...) to names with as few requests as possible.
+func (c *Client) ResolveNames(ctx context.Context, ids []int64) (map[int64]EntityName, error) {
+	names := make(map[int64]EntityName, len(ids))
+	for start := 0; start < len(ids); start += namesChunkSize {
+		end := start + namesChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		var chunk []EntityName
+		if err := c.Post(ctx, "/universe/names/", "", ids[start:end], &chunk); err != nil {
+			return names, err
+		}
+		for _, entry := range chunk {
+			names[entry.ID] = entry
+		}
+	}
+	return names, nil
 }
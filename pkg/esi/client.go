@@ -0,0 +1,390 @@
+package esi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+const (
+	// DefaultBaseURL is the public Tranquility ESI endpoint.
+	DefaultBaseURL = "https://esi.evetech.net/latest"
+	// DefaultUserAgent identifies eve-notify to CCP, as the ESI guidelines ask.
+	DefaultUserAgent = "eve-notify (https://github.com/FabricSoul/eve-notify)"
+
+	// errorLimitFloor is how many errors we leave in the ESI error budget
+	// before pausing all requests until the budget resets.
+	errorLimitFloor = 10
+	// maxRetries is how often a request failing with a gateway error is retried.
+	maxRetries = 3
+	// cacheMaxAge is how long after expiring a persisted entry is kept for
+	// revalidation before it is pruned.
+	cacheMaxAge = 7 * 24 * time.Hour
+)
+
+// Client is a context-aware ESI client with a local HTTP cache and error-limit
+// handling. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	userAgent  string
+	httpClient *http.Client
+
+	cacheDir string // Optional directory where public cache entries are persisted.
+	cache    map[string]*cacheEntry
+	mu       sync.Mutex
+
+	// pausedUntil is set when the error limit is nearly exhausted or a 420 was received.
+	pausedUntil time.Time
+}
+
+// cacheEntry is a cached ESI response, kept until it expires and then
+// revalidated with its ETag.
+type cacheEntry struct {
+	URL     string
+	ETag    string
+	Expires time.Time
+	Pages   int
+	Body    []byte
+}
+
+// Response describes the caching metadata of a completed request.
+type Response struct {
+	Expires   time.Time // When ESI will have new data; pollers should not ask before.
+	Pages     int       // Number of pages for paginated endpoints.
+	FromCache bool      // The body was served from the local cache.
+}
+
+// StatusError is returned when ESI answers with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("ESI returned non-200 status: %s %s", e.Status, e.Body)
+}
+
+// NewClient creates an ESI client. An empty baseURL or userAgent falls back to
+// the defaults. If cacheDir is not empty, cached public responses are also
+// stored there.
+func NewClient(baseURL, userAgent, cacheDir string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+	if cacheDir != "" {
+		if err := os.MkdirAll(cacheDir, 0o755); err != nil {
+			logger.Sugar.Errorf("ESI cache will not be persisted: %v", err)
+			cacheDir = ""
+		}
+	}
+	c := &Client{
+		baseURL:    baseURL,
+		userAgent:  userAgent,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cacheDir:   cacheDir,
+		cache:      make(map[string]*cacheEntry),
+	}
+	c.pruneCache()
+	return c
+}
+
+// BaseURL returns the ESI base URL requests are sent to.
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// Get fetches a public path (relative to the base URL, e.g. "/status/") and
+// decodes the JSON body into out. A fresh cached response is returned without
+// a request; a stale one is revalidated with If-None-Match.
+func (c *Client) Get(ctx context.Context, path string, out interface{}) (*Response, error) {
+	url := c.baseURL + path
+	return c.get(ctx, url, url, "", true, out)
+}
+
+// GetAuthorized fetches an authenticated path on behalf of a character, with
+// token sent as the bearer token. Its responses are cached per character, so
+// they survive token refreshes, and only in memory, so private data never
+// reaches the disk.
+func (c *Client) GetAuthorized(ctx context.Context, path string, charID int64, token string, out interface{}) (*Response, error) {
+	url := c.baseURL + path
+	return c.get(ctx, url, fmt.Sprintf("%s|%d", url, charID), token, false, out)
+}
+
+// get serves url from the cache entry under key or fetches it. Entries are
+// written to disk only if persist is set.
+func (c *Client) get(ctx context.Context, url, key, token string, persist bool, out interface{}) (*Response, error) {
+	entry := c.cachedEntry(key, persist)
+	if entry != nil && time.Now().Before(entry.Expires) {
+		logger.Sugar.Debugf("ESI cache hit: %s", url)
+		return entry.response(true), entry.decode(out)
+	}
+
+	resp, body, err := c.do(ctx, http.MethodGet, url, token, nil, func(req *http.Request) {
+		if entry != nil && entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		// The entry is shared with concurrent readers, so it is replaced, not changed.
+		revalidated := *entry
+		revalidated.Expires = parseExpires(resp.Header)
+		c.storeEntry(key, &revalidated, persist)
+		return revalidated.response(true), revalidated.decode(out)
+	case resp.StatusCode == http.StatusOK:
+		entry = &cacheEntry{
+			URL:     url,
+			ETag:    resp.Header.Get("ETag"),
+			Expires: parseExpires(resp.Header),
+			Pages:   parsePages(resp.Header),
+			Body:    body,
+		}
+		c.storeEntry(key, entry, persist)
+		return entry.response(false), entry.decode(out)
+	default:
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+}
+
+// Post sends a JSON body to path and decodes the JSON answer into out.
+// POST responses are never cached.
+func (c *Client) Post(ctx context.Context, path, token string, in, out interface{}) error {
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode ESI request: %w", err)
+	}
+	resp, body, err := c.do(ctx, http.MethodPost, c.baseURL+path, token, payload, func(req *http.Request) {
+		req.Header.Set("Content-Type", "application/json")
+	})
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status, Body: string(body)}
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode ESI response: %w", err)
+	}
+	return nil
+}
+
+// do sends a request, honouring the error-limit pause and retrying gateway
+// errors with a growing delay. It returns the response with its body read.
+func (c *Client) do(ctx context.Context, method, url, token string, payload []byte, prepare func(*http.Request)) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.waitForErrorLimit(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create ESI request: %w", err)
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+		req.Header.Set("Accept", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		prepare(req)
+
+		logger.Sugar.Debugf("ESI %s %s", method, url)
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to make request to ESI: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read ESI response: %w", err)
+		}
+
+		c.trackErrorLimit(resp)
+
+		retryable := resp.StatusCode == http.StatusBadGateway ||
+			resp.StatusCode == http.StatusServiceUnavailable ||
+			resp.StatusCode == http.StatusGatewayTimeout
+		if !retryable || attempt >= maxRetries {
+			return resp, body, nil
+		}
+
+		delay := time.Duration(1<<attempt) * time.Second
+		logger.Sugar.Warnf("ESI returned %s for %s, retrying in %s.", resp.Status, url, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// trackErrorLimit pauses the client when ESI says we are close to, or over,
+// the error limit.
+func (c *Client) trackErrorLimit(resp *http.Response) {
+	reset := 60 * time.Second
+	if seconds, err := strconv.Atoi(resp.Header.Get("X-ESI-Error-Limit-Reset")); err == nil {
+		reset = time.Duration(seconds) * time.Second
+	}
+
+	limited := resp.StatusCode == 420
+	if remain, err := strconv.Atoi(resp.Header.Get("X-ESI-Error-Limit-Remain")); err == nil && remain < errorLimitFloor {
+		limited = true
+	}
+	if !limited {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until := time.Now().Add(reset)
+	if until.After(c.pausedUntil) {
+		logger.Sugar.Warnf("ESI error limit reached, pausing requests for %s.", reset)
+		c.pausedUntil = until
+	}
+}
+
+// waitForErrorLimit blocks while the client is paused by the error limit.
+func (c *Client) waitForErrorLimit(ctx context.Context) error {
+	c.mu.Lock()
+	wait := time.Until(c.pausedUntil)
+	c.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cachedEntry returns the cache entry for a key, loading persisted entries
+// from disk if needed.
+func (c *Client) cachedEntry(key string, persist bool) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.cache[key]; ok {
+		return entry
+	}
+	if c.cacheDir == "" || !persist {
+		return nil
+	}
+	raw, err := os.ReadFile(c.cacheFile(key))
+	if err != nil {
+		return nil
+	}
+	entry := &cacheEntry{}
+	if err := json.Unmarshal(raw, entry); err != nil {
+		return nil
+	}
+	c.cache[key] = entry
+	return entry
+}
+
+// storeEntry saves a cache entry in memory and, if persist is set and a cache
+// directory is configured, on disk.
+func (c *Client) storeEntry(key string, entry *cacheEntry, persist bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache[key] = entry
+	if c.cacheDir == "" || !persist {
+		return
+	}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(c.cacheFile(key), raw, 0o600); err != nil {
+		logger.Sugar.Debugf("Failed to persist ESI cache entry: %v", err)
+	}
+}
+
+// cacheFile returns the on-disk location of a cache entry. Keys are hashed
+// to make safe file names.
+func (c *Client) cacheFile(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(c.cacheDir, hex.EncodeToString(sum[:])+".json")
+}
+
+// pruneCache removes persisted entries that expired long ago, and those of
+// older versions, which could hold authenticated responses.
+func (c *Client) pruneCache() {
+	if c.cacheDir == "" {
+		return
+	}
+	files, err := os.ReadDir(c.cacheDir)
+	if err != nil {
+		logger.Sugar.Warnf("Could not read the ESI cache: %v", err)
+		return
+	}
+	pruned := 0
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(c.cacheDir, file.Name())
+		var entry cacheEntry
+		raw, err := os.ReadFile(path)
+		if err == nil && json.Unmarshal(raw, &entry) == nil && entry.URL != "" && time.Since(entry.Expires) < cacheMaxAge {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			logger.Sugar.Debugf("Failed to prune ESI cache entry: %v", err)
+			continue
+		}
+		pruned++
+	}
+	if pruned > 0 {
+		logger.Sugar.Infof("Pruned %d stale ESI cache entries.", pruned)
+	}
+}
+
+func (e *cacheEntry) response(fromCache bool) *Response {
+	return &Response{Expires: e.Expires, Pages: e.Pages, FromCache: fromCache}
+}
+
+func (e *cacheEntry) decode(out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(e.Body, out); err != nil {
+		return fmt.Errorf("failed to decode ESI response: %w", err)
+	}
+	return nil
+}
+
+// parseExpires reads the Expires header; a missing header means "now".
+func parseExpires(h http.Header) time.Time {
+	if t, err := http.ParseTime(h.Get("Expires")); err == nil {
+		return t
+	}
+	return time.Now()
+}
+
+// parsePages reads the X-Pages header of paginated endpoints.
+func parsePages(h http.Header) int {
+	if pages, err := strconv.Atoi(h.Get("X-Pages")); err == nil && pages > 0 {
+		return pages
+	}
+	return 1
+}
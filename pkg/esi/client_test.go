@@ -0,0 +1,233 @@
+package esi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	sync := logger.Init()
+	code := m.Run()
+	sync()
+	os.Exit(code)
+}
+
+func expiresIn(d time.Duration) string {
+	return time.Now().Add(d).UTC().Format(http.TimeFormat)
+}
+
+func TestGetServesFreshEntriesFromCache(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Expires", expiresIn(time.Minute))
+		w.Header().Set("X-Pages", "3")
+		_, _ = w.Write([]byte(`{"players":42}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "")
+	for i, wantCached := range []bool{false, true} {
+		var status struct{ Players int }
+		resp, err := client.Get(context.Background(), "/status/", &status)
+		if err != nil {
+			t.Fatalf("Get %d: %v", i, err)
+		}
+		if status.Players != 42 || resp.Pages != 3 || resp.FromCache != wantCached {
+			t.Errorf("Get %d = %+v, %+v; want 42 players, 3 pages, cached %t", i, status, resp, wantCached)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server saw %d requests, want 1", n)
+	}
+}
+
+func TestGetRevalidatesStaleEntriesWithETag(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch requests.Add(1) {
+		case 1:
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Expires", expiresIn(-time.Minute))
+			_, _ = w.Write([]byte(`{"players":7}`))
+		case 2:
+			if got := r.Header.Get("If-None-Match"); got != `"v1"` {
+				t.Errorf("If-None-Match = %q, want %q", got, `"v1"`)
+			}
+			w.Header().Set("Expires", expiresIn(time.Minute))
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			t.Error("revalidated entry was requested again before it expired")
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "", "")
+	for i := 0; i < 3; i++ {
+		var status struct{ Players int }
+		resp, err := client.Get(context.Background(), "/status/", &status)
+		if err != nil {
+			t.Fatalf("Get %d: %v", i, err)
+		}
+		if status.Players != 7 {
+			t.Errorf("Get %d decoded %d players, want 7", i, status.Players)
+		}
+		if i > 0 && (!resp.FromCache || !resp.Expires.After(time.Now())) {
+			t.Errorf("Get %d = %+v, want a cached response expiring later", i, resp)
+		}
+	}
+}
+
+func TestGetPersistsOnlyPublicEntries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Expires", expiresIn(time.Hour))
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	client := NewClient(server.URL, "", dir)
+	if _, err := client.Get(ctx, "/incursions/", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetAuthorized(ctx, "/characters/1/mail/", 1, "token-a", nil); err != nil {
+		t.Fatal(err)
+	}
+	// A refreshed token must not miss the cache.
+	resp, err := client.GetAuthorized(ctx, "/characters/1/mail/", 1, "token-b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.FromCache {
+		t.Error("authorized response was not cached across tokens")
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("cache directory holds %d files, want only the public one", len(files))
+	}
+
+	restarted := NewClient(server.URL, "", dir)
+	if resp, err := restarted.Get(ctx, "/incursions/", nil); err != nil || !resp.FromCache {
+		t.Errorf("public entry was not read back from disk: %+v, %v", resp, err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("server saw %d requests, want 2", n)
+	}
+}
+
+func TestNewClientPrunesStaleCacheFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, entry cacheEntry) {
+		raw, _ := json.Marshal(entry)
+		if err := os.WriteFile(filepath.Join(dir, name), raw, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("fresh.json", cacheEntry{URL: "https://esi/status/", Expires: time.Now().Add(-time.Hour)})
+	write("old.json", cacheEntry{URL: "https://esi/status/", Expires: time.Now().Add(-cacheMaxAge - time.Hour)})
+	write("legacy.json", cacheEntry{Expires: time.Now()})
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	NewClient("http://unused", "", dir)
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 || files[0].Name() != "fresh.json" {
+		names := make([]string, 0, len(files))
+		for _, file := range files {
+			names = append(names, file.Name())
+		}
+		t.Errorf("cache directory holds %v, want only fresh.json", names)
+	}
+}
+
+func TestErrorLimitPausesRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		remain string
+	}{
+		{"error limited", 420, "0"},
+		{"budget nearly spent", http.StatusNotFound, "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.Header().Set("X-ESI-Error-Limit-Remain", tt.remain)
+				w.Header().Set("X-ESI-Error-Limit-Reset", "30")
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClient(server.URL, "", "")
+			_, err := client.Get(context.Background(), "/status/", nil)
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Fatalf("Get error = %v, want status %d", err, tt.status)
+			}
+			if wait := time.Until(client.pausedUntil); wait < 25*time.Second || wait > 30*time.Second {
+				t.Errorf("client paused for %s, want about 30s", wait)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if _, err := client.Get(ctx, "/status/", nil); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Get while paused = %v, want the context deadline", err)
+			}
+			if n := requests.Load(); n != 1 {
+				t.Errorf("server saw %d requests, want 1", n)
+			}
+		})
+	}
+}
+
+func TestResolveNamesBatchesRequests(t *testing.T) {
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/universe/names/" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var ids []int64
+		if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		batches = append(batches, len(ids))
+		names := make([]EntityName, 0, len(ids))
+		for _, id := range ids {
+			names = append(names, EntityName{ID: id, Name: "name", Category: "character"})
+		}
+		_ = json.NewEncoder(w).Encode(names)
+	}))
+	defer server.Close()
+
+	ids := make([]int64, 2500)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+	names, err := NewClient(server.URL, "", "").ResolveNames(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != len(ids) {
+		t.Errorf("resolved %d names, want %d", len(names), len(ids))
+	}
+	if len(batches) != 3 || batches[0] != namesChunkSize || batches[1] != namesChunkSize || batches[2] != 500 {
+		t.Errorf("batches = %v, want [1000 1000 500]", batches)
+	}
+}
@@ -0,0 +1,26 @@
+package esi
+
+import "context"
+
+// MarketPrice is one entry of GET /markets/prices/.
+type MarketPrice struct {
+	TypeID        int64   `json:"type_id"`
+	AveragePrice  float64 `json:"average_price"`
+	AdjustedPrice float64 `json:"adjusted_price"`
+}
+
+// GetMarketPrices fetches the average price of every traded type, keyed by
+// type ID. Types without an average are left out.
+func (c *Client) GetMarketPrices(ctx context.Context) (map[int64]float64, error) {
+	var prices []MarketPrice
+	if _, err := c.Get(ctx, "/markets/prices/", &prices); err != nil {
+		return nil, err
+	}
+	byType := make(map[int64]float64, len(prices))
+	for _, price := range prices {
+		if price.AveragePrice > 0 {
+			byType[price.TypeID] = price.AveragePrice
+		}
+	}
+	return byType, nil
+}
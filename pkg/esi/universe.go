@@ -0,0 +1,88 @@
+package esi
+
+import (
+	"context"
+	"fmt"
+)
+
+// Planet is the part of GET /universe/planets/{id}/ we use.
+type Planet struct {
+	PlanetID int64  `json:"planet_id"`
+	Name     string `json:"name"`
+	SystemID int64  `json:"system_id"`
+	TypeID   int64  `json:"type_id"`
+}
+
+// Type is the part of GET /universe/types/{id}/ we use.
+type Type struct {
+	TypeID         int64   `json:"type_id"`
+	Name           string  `json:"name"`
+	GroupID        int64   `json:"group_id"`
+	Volume         float64 `json:"volume"`
+	PackagedVolume float64 `json:"packaged_volume"`
+}
+
+// GetPlanet fetches a planet. Planets cannot be resolved through /universe/names/.
+func (c *Client) GetPlanet(ctx context.Context, id int64) (*Planet, error) {
+	var planet Planet
+	if _, err := c.Get(ctx, fmt.Sprintf("/universe/planets/%d/", id), &planet); err != nil {
+		return nil, err
+	}
+	return &planet, nil
+}
+
+// GetType fetches an item type.
+func (c *Client) GetType(ctx context.Context, id int64) (*Type, error) {
+	var t Type
+	if _, err := c.Get(ctx, fmt.Sprintf("/universe/types/%d/", id), &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ResolveTypeID looks up the ID of an item type by its exact name.
+func (c *Client) ResolveTypeID(ctx context.Context, name string) (int64, error) {
+	var ids struct {
+		InventoryTypes []EntityName `json:"inventory_types"`
+	}
+	if err := c.Post(ctx, "/universe/ids/", "", []string{name}, &ids); err != nil {
+		return 0, err
+	}
+	if len(ids.InventoryTypes) == 0 {
+		return 0, fmt.Errorf("no item type named %q", name)
+	}
+	return ids.InventoryTypes[0].ID, nil
+}
+
+// Constellation is the part of GET /universe/constellations/{id}/ we use.
+type Constellation struct {
+	ConstellationID int64  `json:"constellation_id"`
+	Name            string `json:"name"`
+	RegionID        int64  `json:"region_id"`
+}
+
+// System is the part of GET /universe/systems/{id}/ we use.
+type System struct {
+	SystemID        int64   `json:"system_id"`
+	Name            string  `json:"name"`
+	ConstellationID int64   `json:"constellation_id"`
+	SecurityStatus  float64 `json:"security_status"`
+}
+
+// GetConstellation fetches a constellation.
+func (c *Client) GetConstellation(ctx context.Context, id int64) (*Constellation, error) {
+	var constellation Constellation
+	if _, err := c.Get(ctx, fmt.Sprintf("/universe/constellations/%d/", id), &constellation); err != nil {
+		return nil, err
+	}
+	return &constellation, nil
+}
+
+// GetSystem fetches a solar system.
+func (c *Client) GetSystem(ctx context.Context, id int64) (*System, error) {
+	var system System
+	if _, err := c.Get(ctx, fmt.Sprintf("/universe/systems/%d/", id), &system); err != nil {
+		return nil, err
+	}
+	return &system, nil
+}
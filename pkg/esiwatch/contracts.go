@@ -0,0 +1,89 @@
+package esiwatch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// contractsScope is needed to read a character's contracts.
+const contractsScope = "esi-contracts.read_character_contracts.v1"
+
+// Seen store kinds for contract alerts.
+const (
+	seenContractAssigned = "contract-assigned"
+	seenContractAccepted = "contract-accepted"
+	seenContractFinished = "contract-finished"
+)
+
+// contract is one entry of GET /characters/{id}/contracts/.
+type contract struct {
+	ContractID int64   `json:"contract_id"`
+	IssuerID   int64   `json:"issuer_id"`
+	AssigneeID int64   `json:"assignee_id"`
+	AcceptorID int64   `json:"acceptor_id"`
+	Status     string  `json:"status"`
+	Type       string  `json:"type"`
+	Title      string  `json:"title"`
+	Price      float64 `json:"price"`
+	Reward     float64 `json:"reward"`
+}
+
+// contractPoller reports contracts assigned to the character and the
+// character's own contracts being accepted or completed.
+type contractPoller struct {
+	svc *Service
+}
+
+func (p *contractPoller) name() string  { return "contracts" }
+func (p *contractPoller) scope() string { return contractsScope }
+
+func (p *contractPoller) enabled(settings *subscription.NotificationSettings) bool {
+	return settings.Contracts
+}
+
+func (p *contractPoller) poll(ctx context.Context, pc *pollContext) (time.Time, error) {
+	var contracts []contract
+	resp, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/contracts/", pc.charID), pc.charID, pc.token, &contracts)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Contracts found on the first run are only recorded.
+	announce := p.svc.seen.primed(p.name(), pc.charID)
+	p.svc.seen.prime(p.name(), pc.charID)
+	for _, c := range contracts {
+		var kind, event string
+		switch {
+		case c.AssigneeID == pc.charID && c.Status == "outstanding":
+			kind, event = seenContractAssigned, fmt.Sprintf("%s assigned a contract to you", p.svc.resolveName(ctx, c.IssuerID))
+		case c.IssuerID == pc.charID && c.Status == "in_progress":
+			kind, event = seenContractAccepted, fmt.Sprintf("%s accepted your contract", p.svc.resolveName(ctx, c.AcceptorID))
+		case c.IssuerID == pc.charID && c.Status == "finished":
+			kind, event = seenContractFinished, "Your contract was completed"
+		default:
+			continue
+		}
+		if !p.svc.seen.mark(kind, pc.charID, c.ContractID) || !announce {
+			continue
+		}
+		logger.Sugar.Infof("[%d] Contract %d is %s.", pc.charID, c.ContractID, c.Status)
+		p.svc.notifSvc.Notify("EVE Notify - Contract", fmt.Sprintf("%s: %s: %s.", pc.charName, event, describeContract(c)), true)
+	}
+	return resp.Expires, nil
+}
+
+// describeContract names a contract by its title, or its type and value.
+func describeContract(c contract) string {
+	if c.Title != "" {
+		return fmt.Sprintf("%q (%s)", c.Title, c.Type)
+	}
+	value := c.Price
+	if c.Type == "courier" {
+		value = c.Reward
+	}
+	return fmt.Sprintf("%s for %s ISK", c.Type, formatISK(value))
+}
@@ -0,0 +1,145 @@
+package esiwatch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// Scopes needed to read personal and corporation industry jobs.
+const (
+	industryScope     = "esi-industry.read_character_jobs.v1"
+	corpIndustryScope = "esi-industry.read_corporation_jobs.v1"
+)
+
+// seenCorpIndustry is keyed by corporation instead of character, so alts in
+// the same corporation do not announce the same job twice.
+const seenCorpIndustry = "industry-corp"
+
+// industryJob is one entry of GET /characters/{id}/industry/jobs/ and
+// GET /corporations/{id}/industry/jobs/.
+type industryJob struct {
+	JobID           int64     `json:"job_id"`
+	ActivityID      int       `json:"activity_id"`
+	BlueprintTypeID int64     `json:"blueprint_type_id"`
+	ProductTypeID   int64     `json:"product_type_id"`
+	Runs            int       `json:"runs"`
+	Status          string    `json:"status"`
+	EndDate         time.Time `json:"end_date"`
+}
+
+// industryActivities names the activity IDs used by industry jobs.
+var industryActivities = map[int]string{
+	1:  "Manufacturing",
+	3:  "TE research",
+	4:  "ME research",
+	5:  "Copying",
+	8:  "Invention",
+	9:  "Reaction",
+	11: "Reaction",
+}
+
+// industryPoller reports industry jobs as they finish. Finished jobs are found
+// by end date rather than status, so a cached response is enough to report a
+// job on time; polls are scheduled for the next end date.
+type industryPoller struct {
+	svc *Service
+}
+
+func (p *industryPoller) name() string  { return "industry" }
+func (p *industryPoller) scope() string { return industryScope }
+
+func (p *industryPoller) enabled(settings *subscription.NotificationSettings) bool {
+	return settings.IndustryJobs
+}
+
+func (p *industryPoller) poll(ctx context.Context, pc *pollContext) (time.Time, error) {
+	var jobs []industryJob
+	resp, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/industry/jobs/", pc.charID), pc.charID, pc.token, &jobs)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := resp.Expires
+	finished := p.collect(ctx, p.name(), pc.charID, jobs, &next)
+
+	if pc.settings.IndustryCorpJobs && p.svc.ssoSvc.HasScope(pc.charID, corpIndustryScope) {
+		corpFinished, err := p.pollCorporation(ctx, pc, &next)
+		if err != nil {
+			// Corporation jobs need a director or factory manager role; keep
+			// reporting personal jobs when they cannot be read.
+			logger.Sugar.Warnf("[%d] Could not read corporation industry jobs: %v", pc.charID, err)
+		}
+		finished = append(finished, corpFinished...)
+	}
+
+	if len(finished) == 1 {
+		p.svc.notifSvc.Notify("EVE Notify - Industry", fmt.Sprintf("%s: %s finished.", pc.charName, finished[0]), true)
+	} else if len(finished) > 1 {
+		p.svc.notifSvc.Notify("EVE Notify - Industry", fmt.Sprintf("%s: %d jobs finished: %s.",
+			pc.charName, len(finished), strings.Join(finished, ", ")), true)
+	}
+	return next, nil
+}
+
+// pollCorporation reads the jobs of the character's corporation.
+func (p *industryPoller) pollCorporation(ctx context.Context, pc *pollContext, next *time.Time) ([]string, error) {
+	corpID, err := p.svc.esiClient.GetCharacterCorporationID(ctx, pc.charID)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []industryJob
+	resp, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/corporations/%d/industry/jobs/", corpID), pc.charID, pc.token, &jobs)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Expires.Before(*next) {
+		*next = resp.Expires
+	}
+	return p.collect(ctx, seenCorpIndustry, corpID, jobs, next), nil
+}
+
+// collect returns descriptions of jobs that have ended and were not reported
+// yet, and moves next forward to the earliest job still running. On the first
+// run for an owner, ended jobs are only recorded.
+func (p *industryPoller) collect(ctx context.Context, kind string, ownerID int64, jobs []industryJob, next *time.Time) []string {
+	now := time.Now()
+	announce := p.svc.seen.primed(kind, ownerID)
+	p.svc.seen.prime(kind, ownerID)
+
+	var finished []string
+	for _, job := range jobs {
+		if job.Status != "active" && job.Status != "ready" {
+			continue
+		}
+		if job.EndDate.After(now) {
+			if job.EndDate.Before(*next) {
+				*next = job.EndDate
+			}
+			continue
+		}
+		if !p.svc.seen.mark(kind, ownerID, job.JobID) || !announce {
+			continue
+		}
+		description := p.describe(ctx, job)
+		logger.Sugar.Infof("[%d] Industry job %d finished: %s", ownerID, job.JobID, description)
+		finished = append(finished, description)
+	}
+	return finished
+}
+
+// describe formats a job as e.g. "Manufacturing 10 x Rifter".
+func (p *industryPoller) describe(ctx context.Context, job industryJob) string {
+	activity, ok := industryActivities[job.ActivityID]
+	if !ok {
+		activity = "Industry job"
+	}
+	typeID := job.ProductTypeID
+	if typeID == 0 {
+		typeID = job.BlueprintTypeID
+	}
+	return fmt.Sprintf("%s %d x %s", activity, job.Runs, p.svc.resolveName(ctx, typeID))
+}
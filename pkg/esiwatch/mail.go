@@ -0,0 +1,67 @@
+package esiwatch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// mailScope is needed to read a character's mail headers.
+const mailScope = "esi-mail.read_mail.v1"
+
+// mailHeader is one entry of GET /characters/{id}/mail/.
+type mailHeader struct {
+	MailID    int64     `json:"mail_id"`
+	From      int64     `json:"from"`
+	Subject   string    `json:"subject"`
+	Timestamp time.Time `json:"timestamp"`
+	IsRead    bool      `json:"is_read"`
+	Labels    []int     `json:"labels"`
+}
+
+// mailPoller reports new unread mails with one of the configured labels.
+type mailPoller struct {
+	svc *Service
+}
+
+func (p *mailPoller) name() string  { return "mail" }
+func (p *mailPoller) scope() string { return mailScope }
+
+func (p *mailPoller) enabled(settings *subscription.NotificationSettings) bool {
+	return settings.MailReceived
+}
+
+func (p *mailPoller) poll(ctx context.Context, pc *pollContext) (time.Time, error) {
+	labels := p.svc.configSvc.GetMailLabels()
+	if len(labels) == 0 {
+		return time.Now().Add(errorRetryInterval), nil
+	}
+	var query []string
+	for _, label := range labels {
+		query = append(query, strconv.Itoa(label))
+	}
+
+	var mails []mailHeader
+	resp, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/mail/?labels=%s", pc.charID, strings.Join(query, ",")), pc.charID, pc.token, &mails)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Mail already in the mailbox when the poller first runs is not announced.
+	announce := p.svc.seen.primed(p.name(), pc.charID)
+	p.svc.seen.prime(p.name(), pc.charID)
+	for _, mail := range mails {
+		if !p.svc.seen.mark(p.name(), pc.charID, mail.MailID) || !announce || mail.IsRead {
+			continue
+		}
+		logger.Sugar.Infof("[%d] New mail %d.", pc.charID, mail.MailID)
+		p.svc.notifSvc.Notify("EVE Notify - Mail", fmt.Sprintf("%s: Mail from %s: %s",
+			pc.charName, p.svc.resolveName(ctx, mail.From), mail.Subject), true)
+	}
+	return resp.Expires, nil
+}
@@ -0,0 +1,225 @@
+package esiwatch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// marketScope is needed to read a character's market orders.
+const marketScope = "esi-markets.read_character_orders.v1"
+
+// marketExpiryWarning is how long before an order expires the user is warned.
+const marketExpiryWarning = 24 * time.Hour
+
+// Seen store kinds for market alerts.
+const (
+	seenMarketFilled  = "market-filled"
+	seenMarketPartial = "market-partial"
+	seenMarketOutbid  = "market-outbid"
+	seenMarketExpiry  = "market-expiry"
+)
+
+// marketOrder is one entry of GET /characters/{id}/orders/ and its history.
+type marketOrder struct {
+	OrderID      int64     `json:"order_id"`
+	TypeID       int64     `json:"type_id"`
+	RegionID     int64     `json:"region_id"`
+	LocationID   int64     `json:"location_id"`
+	Price        float64   `json:"price"`
+	VolumeTotal  int64     `json:"volume_total"`
+	VolumeRemain int64     `json:"volume_remain"`
+	IsBuyOrder   bool      `json:"is_buy_order"`
+	Issued       time.Time `json:"issued"`
+	Duration     int       `json:"duration"`
+	State        string    `json:"state"`
+}
+
+// publicOrder is one entry of GET /markets/{region_id}/orders/.
+type publicOrder struct {
+	OrderID    int64   `json:"order_id"`
+	LocationID int64   `json:"location_id"`
+	Price      float64 `json:"price"`
+	IsBuyOrder bool    `json:"is_buy_order"`
+}
+
+// marketPoller reports filled, outbid and expiring market orders.
+type marketPoller struct {
+	svc *Service
+}
+
+func (p *marketPoller) name() string  { return "market" }
+func (p *marketPoller) scope() string { return marketScope }
+
+func (p *marketPoller) enabled(settings *subscription.NotificationSettings) bool {
+	return settings.MarketFilled || settings.MarketPartialFill || settings.MarketOutbid || settings.MarketExpiry
+}
+
+func (p *marketPoller) poll(ctx context.Context, pc *pollContext) (time.Time, error) {
+	var orders []marketOrder
+	resp, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/orders/", pc.charID), pc.charID, pc.token, &orders)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := resp.Expires
+
+	// Remember open orders, so only orders seen open are reported as filled.
+	announce := p.svc.seen.primed(p.name(), pc.charID)
+	p.svc.seen.prime(p.name(), pc.charID)
+	for _, order := range orders {
+		p.svc.seen.mark(p.name(), pc.charID, order.OrderID)
+	}
+
+	if pc.settings.MarketFilled {
+		var history []marketOrder
+		if _, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/orders/history/", pc.charID), pc.charID, pc.token, &history); err != nil {
+			logger.Sugar.Warnf("[%d] Could not read market order history: %v", pc.charID, err)
+		}
+		for _, order := range history {
+			// Filled orders end up in the history as expired with nothing left.
+			if order.VolumeRemain != 0 || order.State != "expired" {
+				continue
+			}
+			if !p.svc.seen.has(p.name(), pc.charID, order.OrderID) {
+				continue
+			}
+			if p.svc.seen.mark(seenMarketFilled, pc.charID, order.OrderID) {
+				p.notify(ctx, pc, order, "filled")
+			}
+		}
+	}
+
+	step := p.svc.configSvc.GetMarketFillStepPercent()
+	var own map[int64]bool
+	if pc.settings.MarketOutbid {
+		own = p.ownOrders(ctx, pc, orders)
+	}
+	for _, order := range orders {
+		if pc.settings.MarketPartialFill && step > 0 && order.VolumeTotal > 0 {
+			// Report every time another step of the order has been filled.
+			filled := int((order.VolumeTotal - order.VolumeRemain) * 100 / order.VolumeTotal)
+			if reached := filled / step; reached > 0 && order.VolumeRemain > 0 {
+				if p.svc.seen.mark(seenMarketPartial, pc.charID, eventID(order.OrderID, int64(reached))) && announce {
+					p.notify(ctx, pc, order, fmt.Sprintf("%d%% filled", filled))
+				}
+			}
+		}
+
+		if pc.settings.MarketExpiry {
+			expires := order.Issued.AddDate(0, 0, order.Duration)
+			if time.Until(expires) <= marketExpiryWarning {
+				if p.svc.seen.mark(seenMarketExpiry, pc.charID, order.OrderID) {
+					p.notify(ctx, pc, order, fmt.Sprintf("expires in %s", time.Until(expires).Round(time.Minute)))
+				}
+			} else {
+				next = earliest(next, expires.Add(-marketExpiryWarning))
+			}
+		}
+
+		if pc.settings.MarketOutbid {
+			best, err := p.bestCompetitor(ctx, order, own)
+			if err != nil {
+				logger.Sugar.Warnf("[%d] Could not read market orders for type %d: %v", pc.charID, order.TypeID, err)
+				continue
+			}
+			// Modifying an order changes its issue date, which re-arms the alert.
+			// Orders already beaten on the first poll are only recorded.
+			if best != 0 && p.svc.seen.mark(seenMarketOutbid, pc.charID, eventID(order.OrderID, order.Issued.Unix())) && announce {
+				verb := "undercut"
+				if order.IsBuyOrder {
+					verb = "outbid"
+				}
+				p.notify(ctx, pc, order, fmt.Sprintf("%s at %s ISK", verb, formatISK(best)))
+			}
+		}
+	}
+	return next, nil
+}
+
+// ownOrders returns the IDs of the open orders of every authorized character,
+// so the user's alts are not reported as competitors. The orders of the other
+// characters are usually still in the ESI cache from their own polls.
+func (p *marketPoller) ownOrders(ctx context.Context, pc *pollContext, orders []marketOrder) map[int64]bool {
+	own := make(map[int64]bool, len(orders))
+	for _, order := range orders {
+		own[order.OrderID] = true
+	}
+	for _, charID := range p.svc.ssoSvc.Authorized() {
+		if charID == pc.charID || !p.svc.ssoSvc.HasScope(charID, marketScope) {
+			continue
+		}
+		token, err := p.svc.ssoSvc.AccessToken(ctx, charID)
+		if err != nil {
+			logger.Sugar.Warnf("[%d] Could not read the market orders of character %d: %v", pc.charID, charID, err)
+			continue
+		}
+		var alt []marketOrder
+		if _, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/orders/", charID), charID, token, &alt); err != nil {
+			logger.Sugar.Warnf("[%d] Could not read the market orders of character %d: %v", pc.charID, charID, err)
+			continue
+		}
+		for _, order := range alt {
+			own[order.OrderID] = true
+		}
+	}
+	return own
+}
+
+// bestCompetitor returns the best price of another order at the same location
+// that beats ours, or 0 if ours is still the best.
+func (p *marketPoller) bestCompetitor(ctx context.Context, order marketOrder, own map[int64]bool) (float64, error) {
+	orderType := "sell"
+	if order.IsBuyOrder {
+		orderType = "buy"
+	}
+	var best float64
+	for page, pages := 1, 1; page <= pages; page++ {
+		var public []publicOrder
+		path := fmt.Sprintf("/markets/%d/orders/?order_type=%s&type_id=%d&page=%d", order.RegionID, orderType, order.TypeID, page)
+		resp, err := p.svc.esiClient.Get(ctx, path, &public)
+		if err != nil {
+			return 0, err
+		}
+		pages = resp.Pages
+		for _, other := range public {
+			if own[other.OrderID] || other.LocationID != order.LocationID {
+				continue
+			}
+			if order.IsBuyOrder && other.Price > order.Price && other.Price > best {
+				best = other.Price
+			}
+			if !order.IsBuyOrder && other.Price < order.Price && (best == 0 || other.Price < best) {
+				best = other.Price
+			}
+		}
+	}
+	return best, nil
+}
+
+// notify sends one market alert for an order.
+func (p *marketPoller) notify(ctx context.Context, pc *pollContext, order marketOrder, event string) {
+	side := "Sell"
+	if order.IsBuyOrder {
+		side = "Buy"
+	}
+	message := fmt.Sprintf("%s order for %s at %s ISK %s.", side, p.svc.resolveName(ctx, order.TypeID), formatISK(order.Price), event)
+	logger.Sugar.Infof("[%d] Market order %d: %s", pc.charID, order.OrderID, event)
+	p.svc.notifSvc.Notify("EVE Notify - Market", fmt.Sprintf("%s: %s", pc.charName, message), true)
+}
+
+// formatISK formats an amount with thousands separators, e.g. 1,234,567.89.
+func formatISK(amount float64) string {
+	whole := fmt.Sprintf("%.2f", amount)
+	intPart, frac := whole[:len(whole)-3], whole[len(whole)-3:]
+	sign := ""
+	if intPart[0] == '-' {
+		sign, intPart = "-", intPart[1:]
+	}
+	for i := len(intPart) - 3; i > 0; i -= 3 {
+		intPart = intPart[:i] + "," + intPart[i:]
+	}
+	return sign + intPart + frac
+}
@@ -0,0 +1,210 @@
+package esiwatch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+	"gopkg.in/yaml.v3"
+)
+
+// notificationScope is needed to read a character's in-game notifications.
+const notificationScope = "esi-characters.read_notifications.v1"
+
+// esiNotification is one entry of GET /characters/{id}/notifications/.
+type esiNotification struct {
+	NotificationID int64     `json:"notification_id"`
+	SenderID       int64     `json:"sender_id"`
+	SenderType     string    `json:"sender_type"`
+	Text           string    `json:"text"`
+	Timestamp      time.Time `json:"timestamp"`
+	Type           string    `json:"type"`
+}
+
+// StructureUnderAttack is the payload of a StructureUnderAttack notification.
+type StructureUnderAttack struct {
+	SolarSystemID    int64   `yaml:"solarsystemID"`
+	StructureID      int64   `yaml:"structureID"`
+	ShieldPercentage float64 `yaml:"shieldPercentage"`
+	ArmorPercentage  float64 `yaml:"armorPercentage"`
+	HullPercentage   float64 `yaml:"hullPercentage"`
+	CorpName         string  `yaml:"corpName"`
+	AllianceName     string  `yaml:"allianceName"`
+}
+
+// StructureStateChange is the payload of StructureLostShields and
+// StructureLostArmor notifications.
+type StructureStateChange struct {
+	SolarSystemID int64 `yaml:"solarsystemID"`
+	StructureID   int64 `yaml:"structureID"`
+}
+
+// StructureFuelAlert is the payload of a StructureFuelAlert notification.
+type StructureFuelAlert struct {
+	SolarSystemID   int64 `yaml:"solarsystemID"`
+	StructureID     int64 `yaml:"structureID"`
+	StructureTypeID int64 `yaml:"structureTypeID"`
+}
+
+// TowerAlert is the payload of a TowerAlertMsg (POS under attack) notification.
+type TowerAlert struct {
+	SolarSystemID int64   `yaml:"solarSystemID"`
+	MoonID        int64   `yaml:"moonID"`
+	ShieldValue   float64 `yaml:"shieldValue"`
+	ArmorValue    float64 `yaml:"armorValue"`
+	HullValue     float64 `yaml:"hullValue"`
+	AggressorID   int64   `yaml:"aggressorID"`
+}
+
+// WarDeclared is the payload of war declaration notifications.
+type WarDeclared struct {
+	AgainstID    int64   `yaml:"againstID"`
+	DeclaredByID int64   `yaml:"declaredByID"`
+	DelayHours   float64 `yaml:"delayHours"`
+}
+
+// SovereigntyEvent is the payload of sovereignty notifications such as
+// SovStructureReinforced, EntosisCaptureStarted and SovCommandNodeEventStarted.
+type SovereigntyEvent struct {
+	SolarSystemID   int64 `yaml:"solarSystemID"`
+	ConstellationID int64 `yaml:"constellationID"`
+	StructureTypeID int64 `yaml:"structureTypeID"`
+}
+
+// notificationPoller reads in-game notifications that never reach the logs.
+type notificationPoller struct {
+	svc *Service
+}
+
+func (p *notificationPoller) name() string  { return "notifications" }
+func (p *notificationPoller) scope() string { return notificationScope }
+
+func (p *notificationPoller) enabled(settings *subscription.NotificationSettings) bool {
+	return settings.StructureAttacks || settings.StructureFuel || settings.WarDeclarations || settings.SovereigntyChanges
+}
+
+func (p *notificationPoller) poll(ctx context.Context, pc *pollContext) (time.Time, error) {
+	var notifications []esiNotification
+	resp, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/notifications/", pc.charID), pc.charID, pc.token, &notifications)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// On the very first poll, only remember what is already there.
+	announce := p.svc.seen.primed(p.name(), pc.charID)
+	p.svc.seen.prime(p.name(), pc.charID)
+	for _, n := range notifications {
+		if !p.svc.seen.mark(p.name(), pc.charID, n.NotificationID) || !announce {
+			continue
+		}
+		title, message, ok := p.describe(ctx, pc.settings, n)
+		if !ok {
+			continue
+		}
+		logger.Sugar.Infof("[%d] In-game notification %d: %s", pc.charID, n.NotificationID, n.Type)
+		p.svc.notifSvc.Notify(title, fmt.Sprintf("%s: %s", pc.charName, message), true)
+	}
+	return resp.Expires, nil
+}
+
+// describe turns a known notification into a title and message, if the
+// character wants to hear about its type.
+func (p *notificationPoller) describe(ctx context.Context, settings *subscription.NotificationSettings, n esiNotification) (string, string, bool) {
+	switch n.Type {
+	case "StructureUnderAttack":
+		if !settings.StructureAttacks {
+			return "", "", false
+		}
+		var payload StructureUnderAttack
+		if !decodeText(n, &payload) {
+			return "", "", false
+		}
+		attacker := payload.CorpName
+		if payload.AllianceName != "" {
+			attacker = fmt.Sprintf("%s (%s)", payload.CorpName, payload.AllianceName)
+		}
+		return "EVE Notify - Structure Attack", fmt.Sprintf("Structure in %s under attack by %s. Shield %.0f%%, armor %.0f%%, hull %.0f%%.",
+			p.svc.resolveName(ctx, payload.SolarSystemID), attacker,
+			payload.ShieldPercentage, payload.ArmorPercentage, payload.HullPercentage), true
+
+	case "StructureLostShields", "StructureLostArmor":
+		if !settings.StructureAttacks {
+			return "", "", false
+		}
+		var payload StructureStateChange
+		if !decodeText(n, &payload) {
+			return "", "", false
+		}
+		layer := "shields"
+		if n.Type == "StructureLostArmor" {
+			layer = "armor"
+		}
+		return "EVE Notify - Structure Attack", fmt.Sprintf("Structure in %s lost its %s and is reinforced.",
+			p.svc.resolveName(ctx, payload.SolarSystemID), layer), true
+
+	case "TowerAlertMsg":
+		if !settings.StructureAttacks {
+			return "", "", false
+		}
+		var payload TowerAlert
+		if !decodeText(n, &payload) {
+			return "", "", false
+		}
+		return "EVE Notify - Structure Attack", fmt.Sprintf("Control tower in %s under attack. Shield %.0f%%, armor %.0f%%, hull %.0f%%.",
+			p.svc.resolveName(ctx, payload.SolarSystemID), payload.ShieldValue*100, payload.ArmorValue*100, payload.HullValue*100), true
+
+	case "StructureFuelAlert":
+		if !settings.StructureFuel {
+			return "", "", false
+		}
+		var payload StructureFuelAlert
+		if !decodeText(n, &payload) {
+			return "", "", false
+		}
+		return "EVE Notify - Structure Fuel", fmt.Sprintf("%s in %s is running low on fuel.",
+			p.svc.resolveName(ctx, payload.StructureTypeID), p.svc.resolveName(ctx, payload.SolarSystemID)), true
+
+	case "WarDeclared", "CorpWarDeclaredMsg", "AllWarDeclaredMsg", "CorpWarDeclaredV2", "AllWarDeclaredV2":
+		if !settings.WarDeclarations {
+			return "", "", false
+		}
+		var payload WarDeclared
+		if !decodeText(n, &payload) {
+			return "", "", false
+		}
+		return "EVE Notify - War", fmt.Sprintf("%s declared war on %s. Fighting starts in %.0f hours.",
+			p.svc.resolveName(ctx, payload.DeclaredByID), p.svc.resolveName(ctx, payload.AgainstID), payload.DelayHours), true
+
+	case "SovStructureReinforced", "EntosisCaptureStarted", "SovCommandNodeEventStarted", "SovStructureDestroyed", "SovAllClaimLostMsg":
+		if !settings.SovereigntyChanges {
+			return "", "", false
+		}
+		var payload SovereigntyEvent
+		if !decodeText(n, &payload) {
+			return "", "", false
+		}
+		return "EVE Notify - Sovereignty", fmt.Sprintf("%s in %s.",
+			sovereigntyEventNames[n.Type], p.svc.resolveName(ctx, payload.SolarSystemID)), true
+	}
+	return "", "", false
+}
+
+// sovereigntyEventNames are readable descriptions of sovereignty notification types.
+var sovereigntyEventNames = map[string]string{
+	"SovStructureReinforced":     "Sovereignty structure reinforced",
+	"EntosisCaptureStarted":      "Entosis capture started",
+	"SovCommandNodeEventStarted": "Command nodes spawning",
+	"SovStructureDestroyed":      "Sovereignty structure destroyed",
+	"SovAllClaimLostMsg":         "Sovereignty claim lost",
+}
+
+// decodeText parses the YAML text of a notification into a typed payload.
+func decodeText(n esiNotification, payload interface{}) bool {
+	if err := yaml.Unmarshal([]byte(n.Text), payload); err != nil {
+		logger.Sugar.Warnf("Could not parse %s notification %d: %v", n.Type, n.NotificationID, err)
+		return false
+	}
+	return true
+}
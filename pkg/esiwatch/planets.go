@@ -0,0 +1,226 @@
+package esiwatch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// planetsScope is needed to read a character's colonies.
+const planetsScope = "esi-planets.manage_planets.v1"
+
+// Seen store kinds for planetary interaction alerts.
+const (
+	seenPlanetExpiring = "pi-expiring"
+	seenPlanetExpired  = "pi-expired"
+	seenPlanetStorage  = "pi-storage"
+	seenPlanetFull     = "pi-full"
+)
+
+// Storage capacities in m3 of the pins that collect extracted materials.
+// Launchpads and storage facilities exist in one variant per planet type.
+var planetStorageCapacity = map[int64]float64{
+	// Launchpads
+	2256: 10000, 2542: 10000, 2543: 10000, 2544: 10000,
+	2552: 10000, 2555: 10000, 2556: 10000, 2557: 10000,
+	// Storage facilities
+	2257: 12000, 2535: 12000, 2536: 12000, 2541: 12000,
+	2558: 12000, 2560: 12000, 2561: 12000, 2562: 12000,
+}
+
+// colony is one entry of GET /characters/{id}/planets/.
+type colony struct {
+	PlanetID      int64     `json:"planet_id"`
+	SolarSystemID int64     `json:"solar_system_id"`
+	PlanetType    string    `json:"planet_type"`
+	LastUpdate    time.Time `json:"last_update"`
+}
+
+// colonyLayout is the part of GET /characters/{id}/planets/{planet_id}/ we use.
+type colonyLayout struct {
+	Pins []struct {
+		PinID      int64      `json:"pin_id"`
+		TypeID     int64      `json:"type_id"`
+		ExpiryTime *time.Time `json:"expiry_time"`
+		Contents   []struct {
+			TypeID int64   `json:"type_id"`
+			Amount float64 `json:"amount"`
+		} `json:"contents"`
+		ExtractorDetails *struct {
+			CycleTime     int64 `json:"cycle_time"`
+			ProductTypeID int64 `json:"product_type_id"`
+			QtyPerCycle   int64 `json:"qty_per_cycle"`
+		} `json:"extractor_details"`
+	} `json:"pins"`
+}
+
+// PlanetState summarizes a colony for the dashboard.
+type PlanetState struct {
+	PlanetID    int64
+	Name        string
+	Type        string
+	NextExpiry  time.Time // Earliest extractor expiry; zero without running extractors.
+	StorageFull time.Time // Estimated time storage fills; zero if it never will.
+}
+
+// planetPoller reports extractors that are about to stop and storage that is
+// about to fill.
+type planetPoller struct {
+	svc *Service
+}
+
+func (p *planetPoller) name() string  { return "planets" }
+func (p *planetPoller) scope() string { return planetsScope }
+
+func (p *planetPoller) enabled(settings *subscription.NotificationSettings) bool {
+	return settings.PlanetExtractors || settings.PlanetStorage
+}
+
+func (p *planetPoller) poll(ctx context.Context, pc *pollContext) (time.Time, error) {
+	var colonies []colony
+	resp, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/planets/", pc.charID), pc.charID, pc.token, &colonies)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	next := resp.Expires
+	warn := time.Duration(p.svc.configSvc.GetPlanetWarnMinutes()) * time.Minute
+	var expiring, expired, filling, full, states []string
+	var planets []PlanetState
+
+	for _, c := range colonies {
+		state, err := p.planetState(ctx, pc, c)
+		if err != nil {
+			logger.Sugar.Warnf("[%d] Could not read planet %d: %v", pc.charID, c.PlanetID, err)
+			continue
+		}
+		planets = append(planets, state)
+
+		// Keys include the expiry or last update, so resetting a colony re-arms its alerts.
+		if !state.NextExpiry.IsZero() && pc.settings.PlanetExtractors {
+			expiryID := eventID(c.PlanetID, state.NextExpiry.Unix())
+			switch {
+			case !state.NextExpiry.After(now):
+				if p.svc.seen.mark(seenPlanetExpired, pc.charID, expiryID) {
+					expired = append(expired, state.Name)
+				}
+			case warn > 0 && state.NextExpiry.Sub(now) <= warn:
+				if p.svc.seen.mark(seenPlanetExpiring, pc.charID, expiryID) {
+					expiring = append(expiring, fmt.Sprintf("%s (%s)", state.Name, state.NextExpiry.Sub(now).Round(time.Minute)))
+				}
+				next = earliest(next, state.NextExpiry)
+			default:
+				next = earliest(next, state.NextExpiry.Add(-warn))
+			}
+		}
+		if !state.StorageFull.IsZero() && pc.settings.PlanetStorage {
+			storageID := eventID(c.PlanetID, c.LastUpdate.Unix())
+			switch {
+			case !state.StorageFull.After(now):
+				// Full storage is reported even when warnings are off.
+				if p.svc.seen.mark(seenPlanetFull, pc.charID, storageID) {
+					full = append(full, state.Name)
+				}
+			case warn > 0 && state.StorageFull.Sub(now) <= warn:
+				if p.svc.seen.mark(seenPlanetStorage, pc.charID, storageID) {
+					filling = append(filling, state.Name)
+				}
+				next = earliest(next, state.StorageFull)
+			default:
+				next = earliest(next, state.StorageFull.Add(-warn))
+			}
+		}
+	}
+
+	sort.Slice(planets, func(i, j int) bool { return planets[i].Name < planets[j].Name })
+	p.svc.setPlanets(pc.charID, planets)
+
+	if len(expired) > 0 {
+		states = append(states, "Extractors stopped on "+strings.Join(expired, ", "))
+	}
+	if len(expiring) > 0 {
+		states = append(states, "Extractors stop soon on "+strings.Join(expiring, ", "))
+	}
+	if len(full) > 0 {
+		states = append(states, "Storage full on "+strings.Join(full, ", "))
+	}
+	if len(filling) > 0 {
+		states = append(states, "Storage filling up on "+strings.Join(filling, ", "))
+	}
+	if len(states) > 0 {
+		logger.Sugar.Infof("[%d] Planetary interaction: %s", pc.charID, strings.Join(states, "; "))
+		p.svc.notifSvc.Notify("EVE Notify - Planets", fmt.Sprintf("%s: %s.", pc.charName, strings.Join(states, ". ")), true)
+	}
+	return next, nil
+}
+
+// planetState reads a colony's layout and works out when its extractors stop
+// and when its storage fills at the current extraction rate.
+func (p *planetPoller) planetState(ctx context.Context, pc *pollContext, c colony) (PlanetState, error) {
+	state := PlanetState{PlanetID: c.PlanetID, Type: c.PlanetType, Name: fmt.Sprintf("Planet %d", c.PlanetID)}
+	if planet, err := p.svc.esiClient.GetPlanet(ctx, c.PlanetID); err == nil {
+		state.Name = planet.Name
+	}
+
+	var layout colonyLayout
+	if _, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/planets/%d/", pc.charID, c.PlanetID), pc.charID, pc.token, &layout); err != nil {
+		return state, err
+	}
+
+	var capacity, used, inflow float64 // m3 and m3 per second
+	now := time.Now()
+	for _, pin := range layout.Pins {
+		if pin.ExtractorDetails != nil && pin.ExpiryTime != nil {
+			if state.NextExpiry.IsZero() || pin.ExpiryTime.Before(state.NextExpiry) {
+				state.NextExpiry = *pin.ExpiryTime
+			}
+			details := pin.ExtractorDetails
+			if pin.ExpiryTime.After(now) && details.CycleTime > 0 {
+				inflow += float64(details.QtyPerCycle) * p.volume(ctx, details.ProductTypeID) / float64(details.CycleTime)
+			}
+		}
+		if pinCapacity, ok := planetStorageCapacity[pin.TypeID]; ok {
+			capacity += pinCapacity
+			for _, item := range pin.Contents {
+				used += item.Amount * p.volume(ctx, item.TypeID)
+			}
+		}
+	}
+
+	// Assumes everything extracted ends up in storage, so this errs on the early side.
+	if capacity > 0 {
+		switch {
+		case used >= capacity:
+			state.StorageFull = c.LastUpdate
+		case inflow > 0:
+			state.StorageFull = c.LastUpdate.Add(time.Duration((capacity - used) / inflow * float64(time.Second)))
+			if !state.NextExpiry.IsZero() && state.StorageFull.After(state.NextExpiry) {
+				state.StorageFull = time.Time{}
+			}
+		}
+	}
+	return state, nil
+}
+
+// volume returns the volume of one unit of a type, or 0 if it is unknown.
+func (p *planetPoller) volume(ctx context.Context, typeID int64) float64 {
+	t, err := p.svc.esiClient.GetType(ctx, typeID)
+	if err != nil {
+		return 0
+	}
+	return t.Volume
+}
+
+// earliest returns the earlier of two times.
+func earliest(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,124 @@
+package esiwatch
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/jsonfile"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// seenRetention is how long an entity ID is remembered after it was last seen.
+const seenRetention = 90 * 24 * time.Hour
+
+// seenStore remembers which entities (notifications, mails, contracts, ...)
+// have already been reported, so nothing is announced twice across restarts.
+type seenStore struct {
+	path string
+	mu   sync.Mutex
+
+	// Entries maps "<kind>:<character ID>" to the entity IDs seen for it.
+	Entries map[string]map[int64]time.Time
+}
+
+func loadSeenStore(path string) *seenStore {
+	store := &seenStore{path: path, Entries: make(map[string]map[int64]time.Time)}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Sugar.Warnf("Could not read seen store %s: %v", path, err)
+		}
+		return store
+	}
+	if err := json.Unmarshal(raw, store); err != nil {
+		logger.Sugar.Warnf("Discarding unreadable seen store %s: %v", path, err)
+		store.Entries = make(map[string]map[int64]time.Time)
+	}
+	return store
+}
+
+// primed reports whether anything was ever recorded for a kind and character.
+// Pollers use this to silently record existing entities on their first run
+// instead of announcing the whole history.
+func (s *seenStore) primed(kind string, charID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.Entries[seenKey(kind, charID)]
+	return ok
+}
+
+// mark records an entity and reports whether it had not been seen before.
+func (s *seenStore) mark(kind string, charID, id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := seenKey(kind, charID)
+	ids, ok := s.Entries[key]
+	if !ok {
+		ids = make(map[int64]time.Time)
+		s.Entries[key] = ids
+	}
+	_, seen := ids[id]
+	ids[id] = time.Now()
+	return !seen
+}
+
+// has reports whether an entity was recorded before, without recording it.
+func (s *seenStore) has(kind string, charID, id int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.Entries[seenKey(kind, charID)][id]
+	return ok
+}
+
+// prime records that a poller has run for a kind and character, even if it
+// found nothing yet.
+func (s *seenStore) prime(kind string, charID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := seenKey(kind, charID)
+	if _, ok := s.Entries[key]; !ok {
+		s.Entries[key] = make(map[int64]time.Time)
+	}
+}
+
+// forget drops everything recorded for a kind and character.
+func (s *seenStore) forget(kind string, charID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.Entries, seenKey(kind, charID))
+}
+
+// save prunes old entries and writes the store to disk.
+func (s *seenStore) save() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := time.Now().Add(-seenRetention)
+	for _, ids := range s.Entries {
+		for id, seen := range ids {
+			if seen.Before(cutoff) {
+				delete(ids, id)
+			}
+		}
+	}
+
+	jsonfile.Save(s.path, "seen store", s)
+}
+
+func seenKey(kind string, charID int64) string {
+	return fmt.Sprintf("%s:%d", kind, charID)
+}
+
+// eventID combines several values into one ID for the seen store, for events
+// that have no ID of their own.
+func eventID(parts ...int64) int64 {
+	h := fnv.New64a()
+	for _, part := range parts {
+		binary.Write(h, binary.LittleEndian, part)
+	}
+	return int64(h.Sum64())
+}
@@ -0,0 +1,245 @@
+package esiwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/esi"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/notification"
+	"github.com/FabricSoul/eve-notify/pkg/sso"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+const (
+	// tickInterval is how often the service checks whether a poller is due.
+	tickInterval = 30 * time.Second
+	// minPollInterval keeps pollers from hammering ESI when Expires is missing.
+	minPollInterval = time.Minute
+	// errorRetryInterval is how long a failed poller waits before trying again.
+	errorRetryInterval = 5 * time.Minute
+)
+
+// poller fetches one kind of authenticated ESI data for a character and
+// notifies about anything new.
+type poller interface {
+	// name identifies the poller in logs and in the seen store.
+	name() string
+	// scope is the ESI scope the character must have granted.
+	scope() string
+	// enabled reports whether the character's settings ask for this poller.
+	enabled(settings *subscription.NotificationSettings) bool
+	// poll fetches and reports new data and returns when it should run again.
+	poll(ctx context.Context, pc *pollContext) (time.Time, error)
+}
+
+// pollContext is what a poller knows about the character it runs for.
+type pollContext struct {
+	charID   int64
+	charName string
+	token    string
+	settings *subscription.NotificationSettings
+}
+
+// Service runs the authenticated ESI pollers for every character that is both
+// subscribed and authorized.
+type Service struct {
+	configSvc *config.Service
+	esiClient *esi.Client
+	ssoSvc    *sso.Service
+	subSvc    *subscription.Service
+	notifSvc  *notification.Service
+	seen      *seenStore
+	pollers   []poller
+
+	// nextPoll maps "<poller>:<character ID>" to the earliest time it may run again.
+	nextPoll map[string]time.Time
+
+	names   map[int64]string
+	namesMu sync.Mutex
+
+	// skillQueues holds the last known skill queue state per character.
+	skillQueues map[int64]SkillQueueState
+	queueMu     sync.RWMutex
+
+	// planets holds the colonies found by the last planet poll per character.
+	planets   map[int64][]PlanetState
+	planetsMu sync.RWMutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService creates the ESI watch service. Seen entities are kept in dataDir.
+func NewService(cfg *config.Service, esiClient *esi.Client, ssoSvc *sso.Service, subSvc *subscription.Service, notifSvc *notification.Service, dataDir string) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		configSvc:   cfg,
+		esiClient:   esiClient,
+		ssoSvc:      ssoSvc,
+		subSvc:      subSvc,
+		notifSvc:    notifSvc,
+		seen:        loadSeenStore(filepath.Join(dataDir, "esi_seen.json")),
+		nextPoll:    make(map[string]time.Time),
+		names:       make(map[int64]string),
+		skillQueues: make(map[int64]SkillQueueState),
+		planets:     make(map[int64][]PlanetState),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	s.pollers = []poller{
+		&notificationPoller{svc: s},
+		&skillQueuePoller{svc: s},
+		&industryPoller{svc: s},
+		&planetPoller{svc: s},
+		&marketPoller{svc: s},
+		&mailPoller{svc: s},
+		&contractPoller{svc: s},
+		&walletPoller{svc: s},
+	}
+	return s
+}
+
+// Start runs the polling loop. It should be run in a goroutine.
+func (s *Service) Start() {
+	logger.Sugar.Infoln("ESI watch service started.")
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	s.pollDue()
+	for {
+		select {
+		case <-ticker.C:
+			s.pollDue()
+		case <-s.ctx.Done():
+			logger.Sugar.Infoln("ESI watch service shutting down.")
+			return
+		}
+	}
+}
+
+// Stop ends the polling loop and waits for it to finish.
+func (s *Service) Stop() {
+	logger.Sugar.Infoln("Stopping ESI watch service...")
+	s.cancel()
+	s.wg.Wait()
+	logger.Sugar.Infoln("ESI watch service stopped.")
+}
+
+// pollDue runs every poller whose next poll time has passed.
+func (s *Service) pollDue() {
+	polled := false
+	for _, charID := range s.ssoSvc.Authorized() {
+		settings, subscribed := s.subSvc.GetSettings(charID)
+		if !subscribed {
+			continue
+		}
+		for _, p := range s.pollers {
+			if !p.enabled(settings) || !s.ssoSvc.HasScope(charID, p.scope()) {
+				continue
+			}
+			key := fmt.Sprintf("%s:%d", p.name(), charID)
+			if time.Now().Before(s.nextPoll[key]) {
+				continue
+			}
+			s.nextPoll[key] = s.runPoller(p, charID, settings)
+			polled = true
+		}
+	}
+	if polled {
+		s.seen.save()
+	}
+}
+
+// runPoller runs one poller for one character and returns its next poll time.
+func (s *Service) runPoller(p poller, charID int64, settings *subscription.NotificationSettings) time.Time {
+	ctx, cancel := context.WithTimeout(s.ctx, time.Minute)
+	defer cancel()
+
+	charName := fmt.Sprintf("Character %d", charID)
+	if auth, ok := s.ssoSvc.Authorization(charID); ok && auth.CharacterName != "" {
+		charName = auth.CharacterName
+	}
+	token, err := s.ssoSvc.AccessToken(ctx, charID)
+	if errors.Is(err, sso.ErrRevoked) {
+		s.notifSvc.Notify("EVE Notify - ESI", fmt.Sprintf("%s's ESI authorization was revoked. Authorize it again in the main window.", charName), false)
+	}
+	if err != nil {
+		logger.Sugar.Errorf("[%d] No access token for %s: %v", charID, p.name(), err)
+		return time.Now().Add(errorRetryInterval)
+	}
+
+	logger.Sugar.Debugf("[%d] Polling %s.", charID, p.name())
+	next, err := p.poll(ctx, &pollContext{charID: charID, charName: charName, token: token, settings: settings})
+	if err != nil {
+		logger.Sugar.Errorf("[%d] Polling %s failed: %v", charID, p.name(), err)
+		return time.Now().Add(errorRetryInterval)
+	}
+	if earliest := time.Now().Add(minPollInterval); next.Before(earliest) {
+		next = earliest
+	}
+	return next
+}
+
+// resolveName returns the name of any ESI entity, remembering the answer.
+// Unknown IDs fall back to a placeholder.
+func (s *Service) resolveName(ctx context.Context, id int64) string {
+	s.namesMu.Lock()
+	name, ok := s.names[id]
+	s.namesMu.Unlock()
+	if ok {
+		return name
+	}
+
+	names, err := s.esiClient.ResolveNames(ctx, []int64{id})
+	if entry, found := names[id]; err == nil && found {
+		s.namesMu.Lock()
+		s.names[id] = entry.Name
+		s.namesMu.Unlock()
+		return entry.Name
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+// SkillQueueState is what the last skill queue poll found for a character.
+type SkillQueueState struct {
+	End    time.Time // When the last queued skill finishes; zero if the queue is empty.
+	Paused bool
+}
+
+// SkillQueue returns the last known skill queue state of a character. ok is
+// false until the queue has been polled at least once.
+func (s *Service) SkillQueue(charID int64) (state SkillQueueState, ok bool) {
+	s.queueMu.RLock()
+	defer s.queueMu.RUnlock()
+	state, ok = s.skillQueues[charID]
+	return state, ok
+}
+
+func (s *Service) setSkillQueue(charID int64, end time.Time, paused bool) {
+	s.queueMu.Lock()
+	defer s.queueMu.Unlock()
+	s.skillQueues[charID] = SkillQueueState{End: end, Paused: paused}
+}
+
+// Planets returns the colonies found by the last planet poll of a character.
+func (s *Service) Planets(charID int64) []PlanetState {
+	s.planetsMu.RLock()
+	defer s.planetsMu.RUnlock()
+	return append([]PlanetState(nil), s.planets[charID]...)
+}
+
+func (s *Service) setPlanets(charID int64, planets []PlanetState) {
+	s.planetsMu.Lock()
+	defer s.planetsMu.Unlock()
+	s.planets[charID] = planets
+}
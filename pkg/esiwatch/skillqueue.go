@@ -0,0 +1,128 @@
+package esiwatch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// skillQueueScope is needed to read a character's skill queue.
+const skillQueueScope = "esi-skills.read_skillqueue.v1"
+
+// Seen store kinds for skill queue warnings. Low queues are keyed by the queue
+// end time so a refilled queue that runs low again is announced again; the
+// empty queue mark is cleared whenever the queue has something in it.
+const (
+	seenSkillQueueLow   = "skillqueue-low"
+	seenSkillQueueEmpty = "skillqueue-empty"
+)
+
+// skillQueueEntry is one entry of GET /characters/{id}/skillqueue/. The dates
+// are missing while the queue is paused.
+type skillQueueEntry struct {
+	SkillID       int64      `json:"skill_id"`
+	FinishedLevel int        `json:"finished_level"`
+	QueuePosition int        `json:"queue_position"`
+	StartDate     *time.Time `json:"start_date"`
+	FinishDate    *time.Time `json:"finish_date"`
+}
+
+// skillQueuePoller reports finished skills and queues that are running out.
+type skillQueuePoller struct {
+	svc *Service
+}
+
+func (p *skillQueuePoller) name() string  { return "skillqueue" }
+func (p *skillQueuePoller) scope() string { return skillQueueScope }
+
+func (p *skillQueuePoller) enabled(settings *subscription.NotificationSettings) bool {
+	return settings.SkillCompleted || settings.SkillQueueLow
+}
+
+func (p *skillQueuePoller) poll(ctx context.Context, pc *pollContext) (time.Time, error) {
+	var queue []skillQueueEntry
+	resp, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/skillqueue/", pc.charID), pc.charID, pc.token, &queue)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	now := time.Now()
+	next := resp.Expires
+	var queueEnd time.Time
+	paused := false
+
+	// Skills already finished when the poller first runs are not announced.
+	announce := p.svc.seen.primed(p.name(), pc.charID)
+	p.svc.seen.prime(p.name(), pc.charID)
+	for _, entry := range queue {
+		if entry.FinishDate == nil {
+			paused = true
+			continue
+		}
+		finish := *entry.FinishDate
+		if finish.After(now) {
+			if finish.After(queueEnd) {
+				queueEnd = finish
+			}
+			// Poll again right when the next skill finishes, not only on cache expiry.
+			if finish.Before(next) {
+				next = finish
+			}
+			continue
+		}
+		// ESI keeps finished skills in the queue until the client next logs in.
+		id := entry.SkillID*10 + int64(entry.FinishedLevel)
+		if !p.svc.seen.mark(p.name(), pc.charID, id) || !announce || !pc.settings.SkillCompleted {
+			continue
+		}
+		skill := fmt.Sprintf("%s %s", p.svc.resolveName(ctx, entry.SkillID), romanLevel(entry.FinishedLevel))
+		logger.Sugar.Infof("[%d] Skill finished: %s", pc.charID, skill)
+		p.svc.notifSvc.Notify("EVE Notify - Skill Trained", fmt.Sprintf("%s: %s finished training.", pc.charName, skill), true)
+	}
+
+	p.svc.setSkillQueue(pc.charID, queueEnd, paused)
+	if !queueEnd.IsZero() {
+		p.svc.seen.forget(seenSkillQueueEmpty, pc.charID)
+	}
+	if paused || !pc.settings.SkillQueueLow {
+		return next, nil
+	}
+
+	if queueEnd.IsZero() {
+		// Nothing is left to train. Reported once until the queue is refilled,
+		// and not at all if it was already empty on the first poll.
+		if p.svc.seen.mark(seenSkillQueueEmpty, pc.charID, 0) && announce {
+			logger.Sugar.Infof("[%d] Skill queue is empty.", pc.charID)
+			p.svc.notifSvc.Notify("EVE Notify - Skill Queue", fmt.Sprintf("%s: Skill queue is empty.", pc.charName), true)
+		}
+		return next, nil
+	}
+
+	warn := time.Duration(p.svc.configSvc.GetSkillQueueWarnMinutes()) * time.Minute
+	if warn <= 0 {
+		return next, nil
+	}
+	remaining := queueEnd.Sub(now)
+	if remaining <= warn {
+		if p.svc.seen.mark(seenSkillQueueLow, pc.charID, queueEnd.Unix()) {
+			logger.Sugar.Infof("[%d] Skill queue ends at %s.", pc.charID, queueEnd)
+			p.svc.notifSvc.Notify("EVE Notify - Skill Queue", fmt.Sprintf("%s: Skill queue runs out in %s.",
+				pc.charName, remaining.Round(time.Minute)), true)
+		}
+	} else if warnAt := queueEnd.Add(-warn); warnAt.Before(next) {
+		next = warnAt
+	}
+	return next, nil
+}
+
+// romanLevel formats a skill level the way the game does.
+func romanLevel(level int) string {
+	levels := []string{"0", "I", "II", "III", "IV", "V"}
+	if level < 0 || level >= len(levels) {
+		return fmt.Sprint(level)
+	}
+	return levels[level]
+}
@@ -0,0 +1,65 @@
+package esiwatch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// walletScope is needed to read a character's wallet journal.
+const walletScope = "esi-wallet.read_character_wallet.v1"
+
+// journalEntry is one entry of GET /characters/{id}/wallet/journal/.
+type journalEntry struct {
+	ID           int64     `json:"id"`
+	Amount       float64   `json:"amount"`
+	RefType      string    `json:"ref_type"`
+	FirstPartyID int64     `json:"first_party_id"`
+	Description  string    `json:"description"`
+	Reason       string    `json:"reason"`
+	Date         time.Time `json:"date"`
+}
+
+// walletPoller reports incoming wallet journal entries above the configured
+// threshold, such as player donations and large bounty payouts.
+type walletPoller struct {
+	svc *Service
+}
+
+func (p *walletPoller) name() string  { return "wallet" }
+func (p *walletPoller) scope() string { return walletScope }
+
+func (p *walletPoller) enabled(settings *subscription.NotificationSettings) bool {
+	return settings.WalletJournal
+}
+
+func (p *walletPoller) poll(ctx context.Context, pc *pollContext) (time.Time, error) {
+	var journal []journalEntry
+	resp, err := p.svc.esiClient.GetAuthorized(ctx, fmt.Sprintf("/characters/%d/wallet/journal/", pc.charID), pc.charID, pc.token, &journal)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	threshold := float64(p.svc.configSvc.GetWalletThresholdMillions()) * 1e6
+	announce := p.svc.seen.primed(p.name(), pc.charID)
+	p.svc.seen.prime(p.name(), pc.charID)
+	for _, entry := range journal {
+		if !p.svc.seen.mark(p.name(), pc.charID, entry.ID) || !announce || entry.Amount < threshold || entry.Amount <= 0 {
+			continue
+		}
+		message := fmt.Sprintf("+%s ISK (%s)", formatISK(entry.Amount), strings.ReplaceAll(entry.RefType, "_", " "))
+		if entry.RefType == "player_donation" {
+			message = fmt.Sprintf("%s sent you %s ISK", p.svc.resolveName(ctx, entry.FirstPartyID), formatISK(entry.Amount))
+			if entry.Reason != "" {
+				message += fmt.Sprintf(": %q", entry.Reason)
+			}
+		}
+		logger.Sugar.Infof("[%d] Wallet journal entry %d: %.2f ISK %s", pc.charID, entry.ID, entry.Amount, entry.RefType)
+		p.svc.notifSvc.Notify("EVE Notify - Wallet", fmt.Sprintf("%s: %s.", pc.charName, message), true)
+	}
+	return resp.Expires, nil
+}
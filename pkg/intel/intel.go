@@ -0,0 +1,158 @@
+package intel
+
+import (
+	"strings"
+
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+)
+
+// Abbreviations shorter than this are too ambiguous to match.
+const minAbbreviation = 3
+
+// Misspelt names this long or longer may be one edit off.
+const minFuzzyLength = 5
+
+// Report is what an intel message says.
+type Report struct {
+	Systems  []*sde.System
+	Ships    []string
+	Clear    bool // "clr": the systems are clear.
+	NoVisual bool // "nv": hostiles reported earlier are out of sight.
+	Status   bool // Someone asks for the status of the systems.
+}
+
+// Hostile reports whether the message reports hostiles, rather than clearing
+// a system or asking about it.
+func (r Report) Hostile() bool {
+	return len(r.Systems) > 0 && !r.Clear && !r.NoVisual && !r.Status
+}
+
+// Parser finds solar systems, ships and keywords in intel messages.
+type Parser struct {
+	universe *sde.Universe
+}
+
+// NewParser creates a parser matching against the given universe data.
+func NewParser(universe *sde.Universe) *Parser {
+	return &Parser{universe: universe}
+}
+
+// Parse reads an intel message. near maps systems close to the subscribed
+// characters to their distance: abbreviated and misspelt names only match
+// those, as the same abbreviation means different systems in different places.
+func (p *Parser) Parse(text string, near map[int64]int) Report {
+	var report Report
+	if strings.Contains(strings.ToLower(text), "no visual") {
+		report.NoVisual = true
+	}
+
+	tokens := tokenize(text)
+	seen := make(map[int64]bool)
+	for i := 0; i < len(tokens); i++ {
+		// Names of up to three words, longest first.
+		if system, words := p.exactName(tokens[i:]); system != nil {
+			if !seen[system.ID] {
+				seen[system.ID] = true
+				report.Systems = append(report.Systems, system)
+			}
+			i += words - 1
+			continue
+		}
+
+		token := strings.ToLower(tokens[i])
+		switch token {
+		case "clr", "clear":
+			report.Clear = true
+			continue
+		case "nv":
+			report.NoVisual = true
+			continue
+		case "status", "stat":
+			report.Status = true
+			continue
+		}
+		if ship, ok := shipName(token); ok {
+			report.Ships = append(report.Ships, ship)
+			continue
+		}
+		if system := p.nearName(token, near); system != nil && !seen[system.ID] {
+			seen[system.ID] = true
+			report.Systems = append(report.Systems, system)
+		}
+	}
+	return report
+}
+
+// exactName matches the longest system name at the start of tokens.
+func (p *Parser) exactName(tokens []string) (*sde.System, int) {
+	for words := min(3, len(tokens)); words > 0; words-- {
+		if system, ok := p.universe.SystemByName(strings.Join(tokens[:words], " ")); ok {
+			return system, words
+		}
+	}
+	return nil, 0
+}
+
+// nearName matches an abbreviated or misspelt name against the nearby
+// systems, if exactly one of them fits.
+func (p *Parser) nearName(token string, near map[int64]int) *sde.System {
+	if len(token) < minAbbreviation {
+		return nil
+	}
+	compactToken := compact(token)
+	var prefixed, misspelt []*sde.System
+	for id := range near {
+		system, ok := p.universe.System(id)
+		if !ok {
+			continue
+		}
+		name := compact(strings.ToLower(system.Name))
+		if strings.HasPrefix(name, compactToken) {
+			prefixed = append(prefixed, system)
+		} else if len(compactToken) >= minFuzzyLength && withinOneEdit(compactToken, name) {
+			misspelt = append(misspelt, system)
+		}
+	}
+	switch {
+	case len(prefixed) == 1:
+		return prefixed[0]
+	case len(prefixed) == 0 && len(misspelt) == 1:
+		return misspelt[0]
+	}
+	return nil
+}
+
+// tokenize splits a message into words without surrounding punctuation.
+func tokenize(text string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(text) {
+		if token := strings.Trim(field, `,.;:!?()[]{}*"'`); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// compact drops the dashes people leave out when typing null-sec names.
+func compact(name string) string {
+	return strings.ReplaceAll(name, "-", "")
+}
+
+// withinOneEdit reports whether a and b differ by at most one inserted,
+// removed or replaced character.
+func withinOneEdit(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	if len(b)-len(a) > 1 {
+		return false
+	}
+	i := 0
+	for i < len(a) && a[i] == b[i] {
+		i++
+	}
+	if len(a) == len(b) {
+		return a[i+min(1, len(a)-i):] == b[i+min(1, len(b)-i):]
+	}
+	return a[i:] == b[i+1:]
+}
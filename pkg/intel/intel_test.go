@@ -0,0 +1,99 @@
+package intel
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+)
+
+func TestMain(m *testing.M) {
+	sync := logger.Init()
+	code := m.Run()
+	sync()
+	os.Exit(code)
+}
+
+func testParser() *Parser {
+	return NewParser(sde.New(sde.Dataset{
+		Systems: []sde.System{
+			{ID: 1, Name: "Jita"},
+			{ID: 2, Name: "Perimeter"},
+			{ID: 3, Name: "1DQ1-A"},
+			{ID: 4, Name: "8WA-Z6"},
+			{ID: 5, Name: "Old Man Star"},
+			{ID: 6, Name: "Period Basis"},
+		},
+	}))
+}
+
+func TestParse(t *testing.T) {
+	p := testParser()
+	near := map[int64]int{3: 0, 4: 1}
+	tests := []struct {
+		name    string
+		text    string
+		near    map[int64]int
+		systems []string
+		ships   []string
+		hostile bool
+	}{
+		{"system and ship", "1DQ1-A Sabre +3", nil, []string{"1DQ1-A"}, []string{"Sabre"}, true},
+		{"punctuation and plurals", "8WA-Z6: 2x Lokis, gate to 1DQ1-A!", nil, []string{"8WA-Z6", "1DQ1-A"}, []string{"Loki"}, true},
+		{"multi-word name", "Old Man Star  Tengu", nil, []string{"Old Man Star"}, []string{"Tengu"}, true},
+		{"repeated system", "Jita jita JITA", nil, []string{"Jita"}, nil, true},
+		{"clear", "1DQ1-A clr", nil, []string{"1DQ1-A"}, nil, false},
+		{"no visual", "no visual on the Loki in Jita", nil, []string{"Jita"}, []string{"Loki"}, false},
+		{"nv", "8WA-Z6 nv", nil, []string{"8WA-Z6"}, nil, false},
+		{"status request", "status 1DQ1-A?", nil, []string{"1DQ1-A"}, nil, false},
+		{"abbreviation nearby", "1dq Sabre", near, []string{"1DQ1-A"}, []string{"Sabre"}, true},
+		{"abbreviation without dash", "8wa", near, []string{"8WA-Z6"}, nil, true},
+		{"abbreviation far away", "1dq Sabre", nil, nil, []string{"Sabre"}, false},
+		{"abbreviation too short", "1d", near, nil, nil, false},
+		{"misspelt nearby", "pertmeter Cyno", map[int64]int{2: 1}, []string{"Perimeter"}, []string{"Cyno"}, true},
+		{"ambiguous abbreviation", "peri", map[int64]int{2: 1, 6: 2}, nil, nil, false},
+		{"chatter", "o7 fly safe", near, nil, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := p.Parse(tt.text, tt.near)
+			var systems []string
+			for _, system := range report.Systems {
+				systems = append(systems, system.Name)
+			}
+			if !reflect.DeepEqual(systems, tt.systems) {
+				t.Errorf("Parse(%q) systems = %q, want %q", tt.text, systems, tt.systems)
+			}
+			if !reflect.DeepEqual(report.Ships, tt.ships) {
+				t.Errorf("Parse(%q) ships = %q, want %q", tt.text, report.Ships, tt.ships)
+			}
+			if report.Hostile() != tt.hostile {
+				t.Errorf("Parse(%q) hostile = %t, want %t", tt.text, report.Hostile(), tt.hostile)
+			}
+		})
+	}
+}
+
+func TestWithinOneEdit(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"perimeter", "perimeter", true},
+		{"pertmeter", "perimeter", true},
+		{"perimeer", "perimeter", true},
+		{"perimeterr", "perimeter", true},
+		{"xperimeter", "perimeter", true},
+		{"", "a", true},
+		{"eprimeter", "perimeter", false},
+		{"perimet", "perimeter", false},
+		{"abc", "xyz", false},
+	}
+	for _, tt := range tests {
+		if got := withinOneEdit(tt.a, tt.b); got != tt.want {
+			t.Errorf("withinOneEdit(%q, %q) = %t, want %t", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
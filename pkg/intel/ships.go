@@ -0,0 +1,55 @@
+package intel
+
+import "strings"
+
+// ships are hull names commonly reported in intel channels. The imported static
+// data only keeps ore types, so this is a fixed list of the usual suspects.
+var ships = []string{
+	// Interdictors, interceptors and other tackle.
+	"Sabre", "Flycatcher", "Heretic", "Eris",
+	"Stiletto", "Malediction", "Ares", "Crow", "Claw", "Crusader", "Taranis", "Raptor",
+	"Broadsword", "Onyx", "Devoter", "Phobos",
+	// Covert ops and bombers.
+	"Buzzard", "Anathema", "Helios", "Cheetah",
+	"Hound", "Manticore", "Nemesis", "Purifier",
+	// Strategic cruisers and HACs.
+	"Loki", "Legion", "Tengu", "Proteus",
+	"Muninn", "Cerberus", "Ishtar", "Sacrilege", "Zealot", "Deimos", "Vagabond", "Eagle",
+	// Recons.
+	"Falcon", "Rook", "Arazu", "Lachesis", "Curse", "Pilgrim", "Huginn", "Rapier",
+	// Pirate faction hulls.
+	"Stratios", "Astero", "Gila", "Orthrus", "Cynabal", "Vigilant", "Ashimmu", "Garmur",
+	"Worm", "Daredevil", "Succubus", "Dramiel",
+	"Machariel", "Rattlesnake", "Vindicator", "Nightmare", "Bhaalgorn", "Barghest", "Nestor",
+	// Battlecruisers and battleships.
+	"Hurricane", "Drake", "Ferox", "Myrmidon", "Brutix", "Harbinger", "Gnosis",
+	"Typhoon", "Tempest", "Raven", "Dominix", "Megathron", "Apocalypse", "Armageddon",
+	"Praxis", "Leshak",
+	// Capitals, by class and by hull.
+	"Dreadnought", "Carrier", "Supercarrier", "Titan", "Rorqual",
+	"Naglfar", "Moros", "Phoenix", "Revelation", "Zirnitra",
+	"Thanatos", "Archon", "Chimera", "Nidhoggur", "Apostle", "Lif", "Minokawa", "Ninazu",
+	"Nyx", "Aeon", "Wyvern", "Hel", "Avatar", "Erebus", "Leviathan", "Ragnarok",
+	// Triglavian and EDENCOM hulls.
+	"Damavik", "Kikimora", "Vedmak", "Ikitursa", "Drekavac", "Zarmazd",
+	"Skybreaker", "Stormbringer", "Thunderchild",
+	// Shorthand for fits that often precede a drop.
+	"Cyno", "Blops",
+}
+
+var shipsByName = func() map[string]string {
+	byName := make(map[string]string, len(ships))
+	for _, ship := range ships {
+		byName[strings.ToLower(ship)] = ship
+	}
+	return byName
+}()
+
+// shipName matches a lower-case word, singular or plural, to a ship name.
+func shipName(token string) (string, bool) {
+	if ship, ok := shipsByName[token]; ok {
+		return ship, true
+	}
+	ship, ok := shipsByName[strings.TrimSuffix(token, "s")]
+	return ship, ok
+}
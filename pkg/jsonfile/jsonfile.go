@@ -0,0 +1,46 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// Load reads the JSON file at path into v. A missing file leaves v as it is,
+// and so does an unreadable one, which is logged. what names the contents in
+// log messages, e.g. "bounties".
+func Load[T any](path, what string, v *T) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Sugar.Warnf("Could not read %s: %v", what, err)
+		}
+		return
+	}
+	var loaded T
+	if err := json.Unmarshal(raw, &loaded); err != nil {
+		logger.Sugar.Warnf("Discarding unreadable %s: %v", what, err)
+		return
+	}
+	*v = loaded
+}
+
+// Save writes v to path as JSON. It writes a temporary file first and renames
+// it over path, so a crash never leaves half a file behind. Failures are
+// logged.
+func Save(path, what string, v any) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		logger.Sugar.Errorf("Failed to encode %s: %v", what, err)
+		return
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, raw, 0o644); err != nil {
+		logger.Sugar.Errorf("Failed to write %s: %v", what, err)
+		return
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		logger.Sugar.Errorf("Failed to replace %s: %v", what, err)
+	}
+}
@@ -0,0 +1,46 @@
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	sync := logger.Init()
+	code := m.Run()
+	sync()
+	os.Exit(code)
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	Save(path, "state", map[int64]string{1: "Jita"})
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file was left behind: %v", err)
+	}
+
+	loaded := make(map[int64]string)
+	Load(path, "state", &loaded)
+	if !reflect.DeepEqual(loaded, map[int64]string{1: "Jita"}) {
+		t.Errorf("loaded %v", loaded)
+	}
+}
+
+func TestLoadKeepsValueWithoutReadableFile(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(broken, []byte(`{"1": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{filepath.Join(dir, "missing.json"), broken} {
+		v := map[int64]string{2: "Amarr"}
+		Load(path, "state", &v)
+		if !reflect.DeepEqual(v, map[int64]string{2: "Amarr"}) {
+			t.Errorf("Load(%s) changed the value to %v", filepath.Base(path), v)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package location
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/jsonfile"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// Location is where a character was last seen.
+type Location struct {
+	System    string
+	Previous  string
+	UpdatedAt time.Time
+}
+
+// Service keeps the last known solar system of every character, as learned
+// from jump lines in gamelogs and Local channel changes in chatlogs.
+type Service struct {
+	path      string
+	mu        sync.RWMutex
+	locations map[int64]Location
+	listeners []func(charID int64, loc Location)
+}
+
+// NewService loads the last known locations from dataDir.
+func NewService(dataDir string) *Service {
+	s := &Service{
+		path:      filepath.Join(dataDir, "locations.json"),
+		locations: make(map[int64]Location),
+	}
+	jsonfile.Load(s.path, "locations", &s.locations)
+	return s
+}
+
+// OnChange registers a function called whenever a character changes system.
+func (s *Service) OnChange(fn func(charID int64, loc Location)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// Get returns the last known location of a character.
+func (s *Service) Get(charID int64) (Location, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	loc, ok := s.locations[charID]
+	return loc, ok
+}
+
+// System returns the character's current system, or "" if it is unknown.
+func (s *Service) System(charID int64) string {
+	loc, _ := s.Get(charID)
+	return loc.System
+}
+
+// Set records that a character is in system at the given time. Updates older
+// than the current one are ignored, so replaying a log does not go backwards.
+// It returns the new location and whether the system changed.
+func (s *Service) Set(charID int64, system string, at time.Time) (Location, bool) {
+	return s.set(charID, system, at, true)
+}
+
+// Restore records a location read from the backlog of a log, like Set but
+// without calling the listeners, as the move is old news.
+func (s *Service) Restore(charID int64, system string, at time.Time) {
+	s.set(charID, system, at, false)
+}
+
+func (s *Service) set(charID int64, system string, at time.Time, notify bool) (Location, bool) {
+	s.mu.Lock()
+	loc := s.locations[charID]
+	if system == "" || system == loc.System || at.Before(loc.UpdatedAt) {
+		s.mu.Unlock()
+		return loc, false
+	}
+	loc = Location{System: system, Previous: loc.System, UpdatedAt: at}
+	s.locations[charID] = loc
+	var listeners []func(int64, Location)
+	if notify {
+		listeners = append(listeners, s.listeners...)
+	}
+	s.saveLocked()
+	s.mu.Unlock()
+
+	logger.Sugar.Infof("[%d] Now in %s (was %s).", charID, loc.System, loc.Previous)
+	for _, fn := range listeners {
+		fn(charID, loc)
+	}
+	return loc, true
+}
+
+func (s *Service) saveLocked() {
+	jsonfile.Save(s.path, "locations", s.locations)
+}
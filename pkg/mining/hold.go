@@ -0,0 +1,183 @@
+package mining
+
+import (
+	"context"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/jsonfile"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+const (
+	// holdResetGap is how long mining may pause before the hold is assumed
+	// to have been emptied.
+	holdResetGap = 10 * time.Minute
+	// rateWindow is how far back cycles count towards the mining rate.
+	rateWindow = 10 * time.Minute
+)
+
+// holdCapacity is an ore hold size, either entered by the user or learned
+// from the volume mined when the client reported the hold full.
+type holdCapacity struct {
+	Capacity float64
+	Learned  bool
+}
+
+// holdCycle is the volume one cycle added to the hold.
+type holdCycle struct {
+	at     time.Time
+	volume float64
+}
+
+// hold is what a character mined since the hold was last emptied.
+type hold struct {
+	filled float64
+	cycles []holdCycle // Within rateWindow of the latest.
+	full   bool        // The client reported it full; the next cycle starts over.
+	warned bool
+}
+
+// HoldState is a prediction of when an ore hold fills up.
+type HoldState struct {
+	Filled    float64       // m³ mined since the hold was emptied.
+	Capacity  float64       // m³, 0 if unknown.
+	PerHour   float64       // m³ mined per hour recently, 0 until two cycles were seen.
+	UntilFull time.Duration // 0 unless both capacity and rate are known.
+}
+
+// Known reports whether a time until full could be estimated.
+func (h HoldState) Known() bool {
+	return h.Capacity > 0 && h.PerHour > 0
+}
+
+// HoldCapacity returns a character's ore hold size and whether it was learned.
+func (s *Service) HoldCapacity(charID int64) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c := s.capacities[charID]
+	return c.Capacity, c.Learned
+}
+
+// SetHoldCapacity sets a character's ore hold size in m³. 0 learns it again
+// the next time the hold fills.
+func (s *Service) SetHoldCapacity(charID int64, capacity float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if capacity <= 0 {
+		delete(s.capacities, charID)
+	} else {
+		s.capacities[charID] = holdCapacity{Capacity: capacity}
+	}
+	s.saveCapacitiesLocked()
+	logger.Sugar.Infof("[%d] Set ore hold capacity to: %.0f m³", charID, capacity)
+}
+
+// HoldVolume returns the volume of one unit of an ore without waiting for
+// ESI, as it is called while following a gamelog. An ore not known yet is
+// looked up in the background, and its cycles don't count towards the hold
+// until the lookup is done.
+func (s *Service) HoldVolume(ore string) (float64, bool) {
+	if known, ok := s.universe.OreByName(ore); ok {
+		return known.Volume, true
+	}
+	s.mu.Lock()
+	volume, ok := s.volumes[ore]
+	looking := s.lookups[ore]
+	if !ok && !looking {
+		s.lookups[ore] = true
+	}
+	s.mu.Unlock()
+	if ok {
+		return volume, true
+	}
+	if !looking {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			s.Volume(ctx, ore)
+			s.mu.Lock()
+			delete(s.lookups, ore)
+			s.mu.Unlock()
+		}()
+	}
+	return 0, false
+}
+
+// AddToHold records a cycle's volume in the character's hold. It returns the
+// new prediction and whether it is the first to fall within warnBefore of
+// the hold being full.
+func (s *Service) AddToHold(charID int64, at time.Time, volume float64, warnBefore time.Duration) (HoldState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, ok := s.holds[charID]
+	if !ok || h.full || (len(h.cycles) > 0 && at.Sub(h.cycles[len(h.cycles)-1].at) > holdResetGap) {
+		h = &hold{}
+		s.holds[charID] = h
+	}
+	h.filled += volume
+	h.cycles = append(h.cycles, holdCycle{at: at, volume: volume})
+	for len(h.cycles) > 2 && at.Sub(h.cycles[0].at) > rateWindow {
+		h.cycles = h.cycles[1:]
+	}
+
+	state := s.holdStateLocked(charID, h)
+	if !state.Known() || h.warned || warnBefore <= 0 || state.UntilFull > warnBefore {
+		return state, false
+	}
+	h.warned = true
+	return state, true
+}
+
+// HoldFull records that the client reported the hold full. Unless the user
+// entered a capacity, the volume mined so far becomes the learned capacity.
+func (s *Service) HoldFull(charID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, ok := s.holds[charID]
+	if !ok {
+		return
+	}
+	h.full = true
+	if c, set := s.capacities[charID]; (!set || c.Learned) && h.filled > 0 {
+		s.capacities[charID] = holdCapacity{Capacity: h.filled, Learned: true}
+		s.saveCapacitiesLocked()
+		logger.Sugar.Infof("[%d] Learned ore hold capacity: %.0f m³", charID, h.filled)
+	}
+}
+
+// ResetHolds forgets what every character has mined, after the holds were
+// emptied without a pause long enough to notice.
+func (s *Service) ResetHolds() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.holds = make(map[int64]*hold)
+	logger.Sugar.Infoln("Reset all ore holds.")
+}
+
+func (s *Service) holdStateLocked(charID int64, h *hold) HoldState {
+	state := HoldState{Filled: h.filled, Capacity: s.capacities[charID].Capacity}
+	if n := len(h.cycles); n >= 2 {
+		// The first cycle's volume was mined before the window started.
+		var volume float64
+		for _, cycle := range h.cycles[1:] {
+			volume += cycle.volume
+		}
+		if span := h.cycles[n-1].at.Sub(h.cycles[0].at); span > 0 {
+			state.PerHour = volume / span.Hours()
+		}
+	}
+	if state.Known() {
+		left := max(state.Capacity-state.Filled, 0)
+		state.UntilFull = time.Duration(left / state.PerHour * float64(time.Hour))
+	}
+	return state
+}
+
+func (s *Service) loadCapacities() {
+	jsonfile.Load(s.capacitiesPath, "ore hold capacities", &s.capacities)
+}
+
+func (s *Service) saveCapacitiesLocked() {
+	jsonfile.Save(s.capacitiesPath, "ore hold capacities", s.capacities)
+}
@@ -0,0 +1,253 @@
+package mining
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/esi"
+	"github.com/FabricSoul/eve-notify/pkg/jsonfile"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+	"github.com/FabricSoul/eve-notify/pkg/stats"
+)
+
+const (
+	// maxSessions is how many sessions are kept per character.
+	maxSessions = 100
+	// pricesMaxAge is how long market prices are reused before refetching.
+	pricesMaxAge = time.Hour
+)
+
+// Cycle is one mining result read from a gamelog.
+type Cycle struct {
+	Time     time.Time
+	Ore      string
+	Units    int64 // Units put in the hold.
+	Residue  int64 // Units lost from the asteroid as residue.
+	Critical bool  // Bonus units of a critical success rather than a cycle.
+}
+
+// OreStats totals the mining of one ore.
+type OreStats struct {
+	Units    int64
+	Residue  int64
+	Critical int64 // Bonus units from critical successes, included in Units.
+	Cycles   int
+}
+
+// Session totals the mining of one client session.
+type Session struct {
+	Started time.Time // When the gamelog was started.
+	First   time.Time // The first mining cycle.
+	Last    time.Time // The latest mining cycle.
+	Ores    map[string]*OreStats
+}
+
+// StartedAt is when the session's gamelog was started.
+func (s *Session) StartedAt() time.Time {
+	return s.Started
+}
+
+// Duration is the time between the first and latest cycle.
+func (s *Session) Duration() time.Duration {
+	return s.Last.Sub(s.First)
+}
+
+// Units totals the units of every ore.
+func (s *Session) Units() int64 {
+	var units int64
+	for _, ore := range s.Ores {
+		units += ore.Units
+	}
+	return units
+}
+
+// OreNames returns the mined ores sorted by name.
+func (s *Session) OreNames() []string {
+	names := make([]string, 0, len(s.Ores))
+	for name := range s.Ores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Session) clone() Session {
+	c := *s
+	c.Ores = make(map[string]*OreStats, len(s.Ores))
+	for name, ore := range s.Ores {
+		copied := *ore
+		c.Ores[name] = &copied
+	}
+	return c
+}
+
+// Service collects mining cycles per character and session and keeps them in
+// the data directory.
+type Service struct {
+	path           string
+	capacitiesPath string
+	universe       *sde.Universe
+	esiClient      *esi.Client
+
+	mu         sync.Mutex
+	sessions   map[int64][]*Session // Oldest first.
+	holds      map[int64]*hold
+	capacities map[int64]holdCapacity
+	volumes    map[string]float64 // Ores missing from the static data, looked up on ESI.
+	lookups    map[string]bool    // Ores whose volume is being looked up for a hold.
+	typeIDs    map[string]int64
+	prices     map[int64]float64
+	pricedAt   time.Time
+}
+
+// NewService loads the mining statistics and ore hold sizes kept in dataDir.
+func NewService(dataDir string, universe *sde.Universe, esiClient *esi.Client) *Service {
+	s := &Service{
+		path:           filepath.Join(dataDir, "mining.json"),
+		capacitiesPath: filepath.Join(dataDir, "ore_holds.json"),
+		holds:          make(map[int64]*hold),
+		capacities:     make(map[int64]holdCapacity),
+		universe:       universe,
+		esiClient:      esiClient,
+		sessions:       make(map[int64][]*Session),
+		volumes:        make(map[string]float64),
+		lookups:        make(map[string]bool),
+		typeIDs:        make(map[string]int64),
+	}
+	s.loadCapacities()
+	jsonfile.Load(s.path, "mining statistics", &s.sessions)
+	return s
+}
+
+// Record adds a mining cycle to the session started at started.
+func (s *Service) Record(charID int64, started time.Time, cycle Cycle) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, sessions := stats.Current(s.sessions[charID], started, maxSessions, func() *Session {
+		return &Session{Started: started, First: cycle.Time, Ores: make(map[string]*OreStats)}
+	})
+	s.sessions[charID] = sessions
+
+	ore, ok := session.Ores[cycle.Ore]
+	if !ok {
+		ore = &OreStats{}
+		session.Ores[cycle.Ore] = ore
+	}
+	ore.Units += cycle.Units
+	ore.Residue += cycle.Residue
+	if cycle.Critical {
+		ore.Critical += cycle.Units
+	} else {
+		ore.Cycles++
+	}
+	if cycle.Time.After(session.Last) {
+		session.Last = cycle.Time
+	}
+	s.saveLocked()
+}
+
+// Sessions returns copies of a character's sessions, newest first.
+func (s *Service) Sessions(charID int64) []Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessions := s.sessions[charID]
+	copies := make([]Session, 0, len(sessions))
+	for i := len(sessions) - 1; i >= 0; i-- {
+		copies = append(copies, sessions[i].clone())
+	}
+	return copies
+}
+
+// Characters returns the IDs of every character with mining statistics.
+func (s *Service) Characters() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int64, 0, len(s.sessions))
+	for id := range s.sessions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Volume returns the m³ per unit of an ore, from the static data or else ESI.
+func (s *Service) Volume(ctx context.Context, ore string) (float64, bool) {
+	if known, ok := s.universe.OreByName(ore); ok {
+		return known.Volume, true
+	}
+	s.mu.Lock()
+	volume, ok := s.volumes[ore]
+	s.mu.Unlock()
+	if ok {
+		return volume, true
+	}
+
+	typeID, ok := s.typeID(ctx, ore)
+	if !ok {
+		return 0, false
+	}
+	t, err := s.esiClient.GetType(ctx, typeID)
+	if err != nil {
+		logger.Sugar.Warnf("Could not look up the volume of %s: %v", ore, err)
+		return 0, false
+	}
+	s.mu.Lock()
+	s.volumes[ore] = t.Volume
+	s.mu.Unlock()
+	return t.Volume, true
+}
+
+// Price returns the average market price of one unit of an ore.
+func (s *Service) Price(ctx context.Context, ore string) (float64, bool) {
+	typeID, ok := s.typeID(ctx, ore)
+	if !ok {
+		return 0, false
+	}
+
+	s.mu.Lock()
+	prices, pricedAt := s.prices, s.pricedAt
+	s.mu.Unlock()
+	if prices == nil || time.Since(pricedAt) > pricesMaxAge {
+		fetched, err := s.esiClient.GetMarketPrices(ctx)
+		if err != nil {
+			logger.Sugar.Warnf("Could not read market prices: %v", err)
+		} else {
+			prices = fetched
+			s.mu.Lock()
+			s.prices, s.pricedAt = fetched, time.Now()
+			s.mu.Unlock()
+		}
+	}
+	price, ok := prices[typeID]
+	return price, ok
+}
+
+func (s *Service) typeID(ctx context.Context, ore string) (int64, bool) {
+	if known, ok := s.universe.OreByName(ore); ok {
+		return known.ID, true
+	}
+	s.mu.Lock()
+	id, ok := s.typeIDs[ore]
+	s.mu.Unlock()
+	if ok {
+		return id, true
+	}
+	id, err := s.esiClient.ResolveTypeID(ctx, ore)
+	if err != nil {
+		logger.Sugar.Warnf("Could not look up ore %s: %v", ore, err)
+		return 0, false
+	}
+	s.mu.Lock()
+	s.typeIDs[ore] = id
+	s.mu.Unlock()
+	return id, true
+}
+
+func (s *Service) saveLocked() {
+	jsonfile.Save(s.path, "mining statistics", s.sessions)
+}
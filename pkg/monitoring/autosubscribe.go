@@ -0,0 +1,152 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/character"
+	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/notification"
+	"github.com/FabricSoul/eve-notify/pkg/profile"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// autoSubscriber watches the Gamelogs directory for new client sessions and
+// subscribes their characters according to the auto-subscribe policy.
+type autoSubscriber struct {
+	configSvc  *config.Service
+	subSvc     *subscription.Service
+	profileSvc *profile.Service
+	notifSvc   *notification.Service
+	index      *character.Index
+
+	// autoSubscribed holds characters subscribed by this policy and the path
+	// of the gamelog that triggered it. Manual subscriptions are never touched.
+	autoSubscribed map[int64]string
+	// The index calls onNewFile from whichever goroutine updated it.
+	mu sync.Mutex
+}
+
+func newAutoSubscriber(cfg *config.Service, sub *subscription.Service, prof *profile.Service, notif *notification.Service, index *character.Index) *autoSubscriber {
+	a := &autoSubscriber{
+		configSvc:      cfg,
+		subSvc:         sub,
+		profileSvc:     prof,
+		notifSvc:       notif,
+		index:          index,
+		autoSubscribed: make(map[int64]string),
+	}
+	index.OnNewFile(a.onNewFile)
+	return a
+}
+
+// run polls the Gamelogs directory until the context is cancelled.
+func (a *autoSubscriber) run(ctx context.Context) {
+	logger.Sugar.Debugln("Auto-subscriber started.")
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			a.scan()
+		case <-ctx.Done():
+			logger.Sugar.Debugln("Auto-subscriber stopping.")
+			return
+		}
+	}
+}
+
+// scan updates the log index, which reports new gamelogs through onNewFile,
+// and then checks for idle auto-subscribed characters.
+func (a *autoSubscriber) scan() {
+	if err := a.index.Update(a.configSvc.GetLogPath()); err != nil {
+		logger.Sugar.Debugf("Auto-subscriber could not update the log index: %v", err)
+		return
+	}
+	a.unsubscribeIdle()
+}
+
+// onNewFile is called by the log index for every newly indexed file. Only
+// gamelogs that are actually being written to count as new sessions, so old
+// files found on the first index build are ignored.
+func (a *autoSubscriber) onNewFile(file character.LogFile) {
+	if file.Type != character.Gamelog || time.Since(file.ModTime) > loginWindow {
+		return
+	}
+	a.onNewSession(file.CharID, file.Path(a.configSvc.GetLogPath()))
+}
+
+// onNewSession subscribes a character whose client just started a new gamelog.
+func (a *autoSubscriber) onNewSession(charID int64, path string) {
+	if !a.configSvc.GetAutoSubscribe() {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, auto := a.autoSubscribed[charID]; auto {
+		// Keep idle tracking on the newest log of an auto-subscribed character.
+		a.autoSubscribed[charID] = path
+		return
+	}
+	if a.subSvc.IsSubscribed(charID) {
+		return
+	}
+
+	// A character's own profile wins over the default auto-subscribe profile.
+	profileName := a.profileSvc.ProfileFor(charID)
+	if profileName == "" {
+		profileName = a.configSvc.GetAutoSubscribeProfile()
+	}
+	settings, ok := a.profileSvc.GetProfile(profileName)
+	if !ok {
+		logger.Sugar.Warnf("Not auto-subscribing character %d: no profile assigned and no valid default profile.", charID)
+		return
+	}
+
+	name := fmt.Sprintf("Character %d", charID)
+	if header, err := character.ReadLogHeader(path); err == nil {
+		name = header.Listener
+	}
+
+	logger.Sugar.Infof("Auto-subscribing character %d with profile '%s'.", charID, profileName)
+	a.subSvc.Subscribe(charID, settings)
+	a.autoSubscribed[charID] = path
+	a.notifSvc.Notify("EVE Notify - Auto-subscribe",
+		fmt.Sprintf("%s: New session detected, now monitoring with profile %s.", name, profileName), false)
+}
+
+// unsubscribeIdle drops auto-subscribed characters whose gamelog has been idle
+// for longer than the configured threshold.
+func (a *autoSubscriber) unsubscribeIdle() {
+	idleMinutes := a.configSvc.GetAutoUnsubscribeMinutes()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for charID, path := range a.autoSubscribed {
+		if !a.subSvc.IsSubscribed(charID) {
+			// The user unsubscribed manually; forget about it.
+			delete(a.autoSubscribed, charID)
+			continue
+		}
+		if idleMinutes <= 0 {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) < time.Duration(idleMinutes)*time.Minute {
+			continue
+		}
+
+		logger.Sugar.Infof("Auto-unsubscribing character %d after %d idle minutes.", charID, idleMinutes)
+		a.subSvc.Unsubscribe(charID)
+		delete(a.autoSubscribed, charID)
+		a.notifSvc.Notify("EVE Notify - Auto-subscribe",
+			fmt.Sprintf("Character %d: Log idle for %d minutes, monitoring stopped.", charID, idleMinutes), false)
+	}
+}
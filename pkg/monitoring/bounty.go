@@ -0,0 +1,96 @@
+package monitoring
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/bounty"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+var (
+	// bountyRegex captures the ISK of a "(bounty)" line, e.g.
+	// "36,250 ISK added to next bounty payout".
+	bountyRegex = regexp.MustCompile(`(\d[\d,. ]*) ISK`)
+	// essRegex captures the share of a bounty paid into the Encounter
+	// Surveillance System, when the client logs it.
+	essRegex = regexp.MustCompile(`(?i)(\d[\d,. ]*) ISK[^.\d]*?\b(?:ESS|Encounter Surveillance System)\b`)
+)
+
+// parseBounty reads a bounty from the text of a "(bounty)" gamelog line.
+func parseBounty(line gamelogLine) (bounty.Bounty, bool) {
+	text := markupRegex.ReplaceAllString(line.Text, "")
+	b := bounty.Bounty{Time: line.Time}
+	if matches := essRegex.FindStringSubmatchIndex(text); matches != nil {
+		b.ESS, _ = parseISK(text[matches[2]:matches[3]])
+		text = text[:matches[0]] + text[matches[1]:]
+	}
+	matches := bountyRegex.FindStringSubmatch(text)
+	if matches == nil {
+		return bounty.Bounty{}, false
+	}
+	amount, ok := parseISK(matches[1])
+	if !ok {
+		return bounty.Bounty{}, false
+	}
+	b.Amount = amount
+	return b, true
+}
+
+// parseISK reads an amount written with the client's thousands and decimal
+// separators, which depend on the user's locale.
+func parseISK(text string) (float64, bool) {
+	text = strings.ReplaceAll(strings.TrimSpace(text), " ", "")
+	lastComma, lastDot := strings.LastIndex(text, ","), strings.LastIndex(text, ".")
+	decimal := -1
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		decimal = max(lastComma, lastDot)
+	case lastDot >= 0 && len(text)-lastDot-1 == 2:
+		decimal = lastDot
+	case lastComma >= 0 && len(text)-lastComma-1 == 2:
+		decimal = lastComma
+	}
+	whole, fraction := text, ""
+	if decimal >= 0 {
+		whole, fraction = text[:decimal], text[decimal+1:]
+	}
+	whole = strings.NewReplacer(",", "", ".", "").Replace(whole)
+	if fraction != "" {
+		whole += "." + fraction
+	}
+	amount, err := strconv.ParseFloat(whole, 64)
+	return amount, err == nil
+}
+
+// finishBounties ends the current ratting session and sends its summary, if
+// it earned any bounties.
+func (m *characterMonitor) finishBounties() {
+	session, ok := m.bountySvc.Finish(m.charID, m.sessionStarted())
+	if !ok {
+		return
+	}
+	message := fmt.Sprintf("Ratting session: %s ISK from %d kills in %s", formatMillions(session.Total), session.Kills,
+		session.Duration().Round(time.Minute))
+	if perHour := session.PerHour(); perHour > 0 {
+		message += fmt.Sprintf(" (%s ISK/h)", formatMillions(perHour))
+	}
+	message += fmt.Sprintf(", %d payout ticks, best %s ISK.", len(session.Ticks), formatMillions(session.BestTick().Amount))
+	if session.ESS > 0 {
+		message += fmt.Sprintf(" %s ISK went to the ESS.", formatMillions(session.ESS))
+	}
+	logger.Sugar.Infof("[%d] %s", m.charID, message)
+
+	settings, exists := m.subSvc.GetSettings(m.charID)
+	if exists && settings.BountySummary {
+		m.notifSvc.Notify("EVE Notify - Ratting", m.message(message), false)
+	}
+}
+
+// formatMillions shortens an ISK amount to millions.
+func formatMillions(isk float64) string {
+	return fmt.Sprintf("%.1fM", isk/1e6)
+}
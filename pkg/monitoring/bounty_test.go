@@ -0,0 +1,92 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/bounty"
+)
+
+func TestParseBounty(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		raw  string
+		want bounty.Bounty
+		ok   bool
+	}{
+		{
+			"client markup",
+			"[ 2024.05.01 12:00:05 ] (bounty) <font size=12><b><color=0xff00aa00>36,250 ISK</b><color=0xaaaaaaaa> added to next bounty payout</font>",
+			bounty.Bounty{Time: at, Amount: 36250},
+			true,
+		},
+		{
+			"dot separator",
+			"[ 2024.05.01 12:00:05 ] (bounty) 1.036.250 ISK added to next bounty payout",
+			bounty.Bounty{Time: at, Amount: 1036250},
+			true,
+		},
+		{
+			"space separator and decimal comma",
+			"[ 2024.05.01 12:00:05 ] (bounty) 36 250,50 ISK added to next bounty payout",
+			bounty.Bounty{Time: at, Amount: 36250.5},
+			true,
+		},
+		{
+			"ESS share",
+			"[ 2024.05.01 12:00:05 ] (bounty) <b>36,250 ISK</b> added to next bounty payout, <b>9,062 ISK</b> paid into the Encounter Surveillance System",
+			bounty.Bounty{Time: at, Amount: 36250, ESS: 9062},
+			true,
+		},
+		{
+			"ESS share first",
+			"[ 2024.05.01 12:00:05 ] (bounty) 9,062 ISK went to the ESS. 36,250 ISK added to next bounty payout",
+			bounty.Bounty{Time: at, Amount: 36250, ESS: 9062},
+			true,
+		},
+		{
+			"no amount",
+			"[ 2024.05.01 12:00:05 ] (bounty) Bounty payout pending",
+			bounty.Bounty{},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseBounty(gamelog(t, tt.raw))
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseBounty = %+v, %t; want %+v, %t", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseISK(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+		ok   bool
+	}{
+		{"250", 250, true},
+		{"36,250", 36250, true},
+		{"36.250", 36250, true},
+		{"36 250", 36250, true},
+		{"1,234,567", 1234567, true},
+		{"1.234.567", 1234567, true},
+		{"36,250.50", 36250.5, true},
+		{"36.250,50", 36250.5, true},
+		{"1 234 567,89", 1234567.89, true},
+		{"99.50", 99.5, true},
+		{"99,50", 99.5, true},
+		{" 36,250 ", 36250, true},
+		{"", 0, false},
+		{"ISK", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseISK(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseISK(%q) = %v, %t; want %v, %t", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package monitoring
+
+import (
+	"context"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// chatlogLine is a single parsed entry of a chatlog, e.g.
+// "[ 2024.05.01 12:00:05 ] EVE System > Channel changed to Local : Jita".
+type chatlogLine struct {
+	Time    time.Time
+	Speaker string
+	Text    string
+}
+
+var (
+	// chatlogLineRegex captures the timestamp, speaker and text of a chatlog line.
+	chatlogLineRegex = regexp.MustCompile(`^\[ (\d{4}\.\d{2}\.\d{2} \d{2}:\d{2}:\d{2}) \] (.+?) > (.*)$`)
+	// localChangedRegex matches the notice posted to Local on entering a system.
+	localChangedRegex = regexp.MustCompile(`^Channel changed to Local : (.+)$`)
+)
+
+// parseChatlogLine splits a raw chatlog line into its parts.
+func parseChatlogLine(raw string) (chatlogLine, bool) {
+	matches := chatlogLineRegex.FindStringSubmatch(strings.TrimPrefix(raw, "\ufeff"))
+	if len(matches) != 4 {
+		return chatlogLine{}, false
+	}
+	t, err := time.Parse("2006.01.02 15:04:05", matches[1])
+	if err != nil {
+		return chatlogLine{}, false
+	}
+	return chatlogLine{Time: t, Speaker: matches[2], Text: strings.TrimSpace(matches[3])}, true
+}
+
+// utf16LineReader reads complete lines from a growing UTF-16LE file, as the
+// client writes chatlogs in that encoding.
+type utf16LineReader struct {
+	r       io.Reader
+	pending []byte // An odd trailing byte not yet decodable.
+	partial string // Text after the last newline.
+}
+
+// readLines returns the complete lines written since the last call.
+func (lr *utf16LineReader) readLines() ([]string, error) {
+	raw, err := io.ReadAll(lr.r)
+	if err != nil {
+		return nil, err
+	}
+	raw = append(lr.pending, raw...)
+	even := len(raw) &^ 1
+	lr.pending = append([]byte(nil), raw[even:]...)
+
+	units := make([]uint16, even/2)
+	for i := range units {
+		units[i] = uint16(raw[2*i]) | uint16(raw[2*i+1])<<8
+	}
+	text := lr.partial + string(utf16.Decode(units))
+	parts := strings.Split(text, "\n")
+	lr.partial = parts[len(parts)-1]
+
+	lines := parts[:len(parts)-1]
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+	return lines, nil
+}
+
+// localChatWorker follows a character's Local chatlog to learn which system it
+// is in. The whole file is read first, so the current system is known even if
+// the client was started before eve-notify. That backlog only updates the
+// location; alerts are for systems entered after it.
+func (m *characterMonitor) localChatWorker(ctx context.Context, filePath string) {
+	logger.Sugar.Infof("[%d] Local chat worker started for file: %s", m.charID, filePath)
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		logger.Sugar.Errorf("[%d] Failed to open Local chatlog: %v", m.charID, err)
+		return
+	}
+	defer file.Close()
+
+	reader := &utf16LineReader{r: file}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	backlog := true
+	for {
+		lines, err := reader.readLines()
+		if err != nil {
+			logger.Sugar.Warnf("[%d] Error reading from Local chatlog: %v", m.charID, err)
+			return
+		}
+		for _, raw := range lines {
+			line, ok := parseChatlogLine(raw)
+			if !ok || line.Speaker != "EVE System" {
+				continue
+			}
+			matches := localChangedRegex.FindStringSubmatch(line.Text)
+			if matches == nil {
+				continue
+			}
+			system := strings.TrimSpace(matches[1])
+			if backlog {
+				m.locationSvc.Restore(m.charID, system, line.Time)
+			} else if loc, changed := m.locationSvc.Set(m.charID, system, line.Time); changed {
+				m.onLocationChanged(loc)
+			}
+		}
+		backlog = false
+
+		select {
+		case <-ctx.Done():
+			logger.Sugar.Infof("[%d] Local chat worker stopped for file: %s", m.charID, filePath)
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package monitoring
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/combat"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+var (
+	// combatHitRegex captures the damage, direction and details of a
+	// "(combat)" line, e.g. "523 to Guristas Pithatis - Scourge Heavy Missile -
+	// Hits" or "112 from Guristas Pithatis - Wrecks".
+	combatHitRegex = regexp.MustCompile(`^(\d[\d,. ]*?) (to|from) (.+)$`)
+	// missOutRegex captures our weapon, its target and the weapon suffix of a
+	// miss, e.g. "Your group of Scourge Heavy Missile misses Guristas Pithatis
+	// completely - Scourge Heavy Missile".
+	missOutRegex = regexp.MustCompile(`^Your (?:group of )?(.+?) misses (.+?) completely(?: - (.+))?$`)
+	// missInRegex captures the attacker and weapon of a miss against us.
+	missInRegex = regexp.MustCompile(`^(.+?) misses you completely(?: - (.+))?$`)
+)
+
+// hitQualities are how well a hit landed, as the client words it.
+var hitQualities = map[string]bool{
+	"wrecks":           true,
+	"smashes":          true,
+	"penetrates":       true,
+	"hits":             true,
+	"glances off":      true,
+	"grazes":           true,
+	"barely scratches": true,
+}
+
+// parseCombat reads a hit or miss from the text of a "(combat)" gamelog line.
+// Other combat notices, e.g. warp scrambles, are not hits.
+func parseCombat(line gamelogLine) (combat.Hit, bool) {
+	text := strings.Join(strings.Fields(markupRegex.ReplaceAllString(line.Text, " ")), " ")
+	hit := combat.Hit{Time: line.Time}
+
+	if matches := missOutRegex.FindStringSubmatch(text); matches != nil {
+		hit.Miss = true
+		hit.Weapon, hit.Other = matches[1], matches[2]
+		return hit, true
+	}
+	if matches := missInRegex.FindStringSubmatch(text); matches != nil {
+		hit.Miss, hit.Incoming = true, true
+		hit.Other, hit.Weapon = matches[1], matches[2]
+		return hit, true
+	}
+
+	matches := combatHitRegex.FindStringSubmatch(text)
+	if matches == nil {
+		return combat.Hit{}, false
+	}
+	damage, ok := parseUnits(matches[1])
+	if !ok {
+		return combat.Hit{}, false
+	}
+	hit.Damage = damage
+	hit.Incoming = matches[2] == "from"
+
+	parts := strings.Split(matches[3], " - ")
+	if n := len(parts); n > 1 && hitQualities[strings.ToLower(parts[n-1])] {
+		hit.Quality = parts[n-1]
+		parts = parts[:n-1]
+	}
+	hit.Other = parts[0]
+	hit.Weapon = strings.Join(parts[1:], " - ")
+	return hit, true
+}
+
+// checkIncomingDPS alerts when the damage taken over the configured period
+// reaches the configured DPS.
+func (m *characterMonitor) checkIncomingDPS(hit combat.Hit, alert bool) {
+	threshold := m.configSvc.GetCombatDPSThreshold()
+	if threshold == 0 {
+		return
+	}
+	seconds := max(m.configSvc.GetCombatDPSSeconds(), 1)
+	dps, high := m.combatSvc.CheckIncoming(m.charID, hit.Time, time.Duration(seconds)*time.Second, float64(threshold))
+	if !high {
+		return
+	}
+	message := fmt.Sprintf("Taking %.0f DPS over the last %d s, latest hit from %s.", dps, seconds, hit.Other)
+	logger.Sugar.Infof("[%d] %s", m.charID, message)
+	if alert {
+		m.notifSvc.Notify("EVE Notify - Combat", m.message(message), true)
+	}
+}
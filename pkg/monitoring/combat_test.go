@@ -0,0 +1,75 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/combat"
+)
+
+func TestParseCombat(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		raw  string
+		want combat.Hit
+		ok   bool
+	}{
+		{
+			"outgoing hit with markup",
+			"[ 2024.05.01 12:00:05 ] (combat) <color=0xff00ffff><b>523</b> <color=0x77ffffff><font size=10>to</font> <b><color=0xffffffff>Guristas Pithatis</b><font size=10><color=0x77ffffff> - Scourge Heavy Missile - Hits",
+			combat.Hit{Time: at, Damage: 523, Quality: "Hits", Weapon: "Scourge Heavy Missile", Other: "Guristas Pithatis"},
+			true,
+		},
+		{
+			"incoming hit without weapon",
+			"[ 2024.05.01 12:00:05 ] (combat) <color=0xffcc0000><b>112</b> <color=0x77ffffff><font size=10>from</font> <b><color=0xffffffff>Guristas Pithatis</b><font size=10><color=0x77ffffff> - Wrecks",
+			combat.Hit{Time: at, Incoming: true, Damage: 112, Quality: "Wrecks", Other: "Guristas Pithatis"},
+			true,
+		},
+		{
+			"comma separator and two-word quality",
+			"[ 2024.05.01 12:00:05 ] (combat) 1,204 from Dread Guristas Shipyard - Glances Off",
+			combat.Hit{Time: at, Incoming: true, Damage: 1204, Quality: "Glances Off", Other: "Dread Guristas Shipyard"},
+			true,
+		},
+		{
+			"space separator",
+			"[ 2024.05.01 12:00:05 ] (combat) 1 204 to Guristas Eliminator - Hobgoblin II - Smashes",
+			combat.Hit{Time: at, Damage: 1204, Quality: "Smashes", Weapon: "Hobgoblin II", Other: "Guristas Eliminator"},
+			true,
+		},
+		{
+			"dot separator without quality",
+			"[ 2024.05.01 12:00:05 ] (combat) 1.204 to Guristas Eliminator - Hobgoblin II",
+			combat.Hit{Time: at, Damage: 1204, Weapon: "Hobgoblin II", Other: "Guristas Eliminator"},
+			true,
+		},
+		{
+			"outgoing miss",
+			"[ 2024.05.01 12:00:05 ] (combat) Your group of <b>Scourge Heavy Missile</b> misses <b>Guristas Pithatis</b> completely - Scourge Heavy Missile",
+			combat.Hit{Time: at, Miss: true, Weapon: "Scourge Heavy Missile", Other: "Guristas Pithatis"},
+			true,
+		},
+		{
+			"incoming miss",
+			"[ 2024.05.01 12:00:05 ] (combat) <b>Guristas Pithatis</b> misses you completely - Light Missile",
+			combat.Hit{Time: at, Miss: true, Incoming: true, Weapon: "Light Missile", Other: "Guristas Pithatis"},
+			true,
+		},
+		{
+			"warp scramble",
+			"[ 2024.05.01 12:00:05 ] (combat) Warp scramble attempt from <b>Guristas Pithatis</b> to you!",
+			combat.Hit{},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseCombat(gamelog(t, tt.raw))
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseCombat = %+v, %t; want %+v, %t", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
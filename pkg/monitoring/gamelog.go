@@ -0,0 +1,48 @@
+package monitoring
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// gamelogLine is a single parsed entry of a gamelog, e.g.
+// "[ 2024.05.01 12:00:05 ] (notify) Ship's cargo hold is full".
+type gamelogLine struct {
+	Time    time.Time
+	Channel string // The message type in parentheses, e.g. "notify" or "mining".
+	Text    string
+}
+
+// gamelogLineRegex captures the timestamp, channel and text of a gamelog line.
+var gamelogLineRegex = regexp.MustCompile(`^\[ (\d{4}\.\d{2}\.\d{2} \d{2}:\d{2}:\d{2}) \] \((\w+)\) (.*)$`)
+
+// parseGamelogLine splits a raw gamelog line into its parts.
+// Timestamps in gamelogs are EVE time, which is UTC.
+func parseGamelogLine(raw string) (gamelogLine, bool) {
+	matches := gamelogLineRegex.FindStringSubmatch(raw)
+	if len(matches) != 4 {
+		return gamelogLine{}, false
+	}
+	t, err := time.Parse("2006.01.02 15:04:05", matches[1])
+	if err != nil {
+		return gamelogLine{}, false
+	}
+	return gamelogLine{Time: t, Channel: matches[2], Text: matches[3]}, true
+}
+
+var (
+	// jumpRegex captures both systems of a "Jumping from X to Y" notice.
+	jumpRegex = regexp.MustCompile(`^Jumping from (.+) to (.+)$`)
+	// markupRegex matches the showinfo links and font tags the client puts around names.
+	markupRegex = regexp.MustCompile(`<[^>]*>`)
+)
+
+// parseJump returns the origin and destination of a jump notice.
+func parseJump(text string) (from, to string, ok bool) {
+	matches := jumpRegex.FindStringSubmatch(markupRegex.ReplaceAllString(text, ""))
+	if len(matches) != 3 {
+		return "", "", false
+	}
+	return strings.TrimSpace(matches[1]), strings.TrimSpace(matches[2]), true
+}
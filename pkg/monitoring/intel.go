@@ -0,0 +1,257 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/character"
+	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/intel"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+)
+
+// intelScanEvery is how many read ticks pass between looking for newer
+// chatlogs of the intel channels.
+const intelScanEvery = 5
+
+// intelLine is a message posted to an intel channel.
+type intelLine struct {
+	Channel string
+	chatlogLine
+}
+
+// intelFile is the chatlog an intel channel is read from.
+type intelFile struct {
+	path   string
+	file   *os.File
+	reader *utf16LineReader
+}
+
+// intelWatcher follows the newest chatlog of each intel channel. Any
+// character in the channel will do, so every message is read once however
+// many characters are subscribed.
+type intelWatcher struct {
+	configSvc *config.Service
+	index     *character.Index
+	files     map[string]*intelFile
+	lines     chan intelLine
+}
+
+func newIntelWatcher(cfg *config.Service, index *character.Index) *intelWatcher {
+	return &intelWatcher{
+		configSvc: cfg,
+		index:     index,
+		files:     make(map[string]*intelFile),
+		lines:     make(chan intelLine, 100),
+	}
+}
+
+// run reads the intel channels until the context is cancelled.
+func (w *intelWatcher) run(ctx context.Context) {
+	logger.Sugar.Debugln("Intel watcher started.")
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	defer w.closeAll()
+
+	for tick := 0; ; tick++ {
+		if tick%intelScanEvery == 0 {
+			w.scan()
+		}
+		w.read(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			logger.Sugar.Debugln("Intel watcher stopping.")
+			return
+		}
+	}
+}
+
+// scan opens the newest chatlog of every configured channel, starting at its
+// end so old reports are not repeated.
+func (w *intelWatcher) scan() {
+	channels := parseChannels(w.configSvc.GetIntelChannels())
+	for channel := range w.files {
+		if !channels[channel] {
+			w.close(channel)
+		}
+	}
+	if len(channels) == 0 {
+		return
+	}
+
+	logPath := w.configSvc.GetLogPath()
+	if err := w.index.Update(logPath); err != nil {
+		logger.Sugar.Errorf("Failed to update log index for intel: %v", err)
+		return
+	}
+	for channel := range channels {
+		latest, ok := w.index.LatestChannel(channel)
+		if !ok {
+			continue
+		}
+		path := latest.Path(logPath)
+		if current, ok := w.files[channel]; ok && current.path == path {
+			continue
+		}
+		w.close(channel)
+
+		file, err := os.Open(path)
+		if err != nil {
+			logger.Sugar.Warnf("Failed to open intel chatlog: %v", err)
+			continue
+		}
+		if _, err := file.Seek(0, io.SeekEnd); err != nil {
+			logger.Sugar.Warnf("Failed to seek intel chatlog: %v", err)
+			file.Close()
+			continue
+		}
+		logger.Sugar.Infof("Reading intel channel %s from %s", channel, path)
+		w.files[channel] = &intelFile{path: path, file: file, reader: &utf16LineReader{r: file}}
+	}
+}
+
+// read passes on the messages posted since the last call.
+func (w *intelWatcher) read(ctx context.Context) {
+	for channel, f := range w.files {
+		lines, err := f.reader.readLines()
+		if err != nil {
+			logger.Sugar.Warnf("Error reading intel channel %s: %v", channel, err)
+			w.close(channel)
+			continue
+		}
+		for _, raw := range lines {
+			line, ok := parseChatlogLine(raw)
+			if !ok || line.Speaker == "EVE System" {
+				continue
+			}
+			select {
+			case w.lines <- intelLine{Channel: channel, chatlogLine: line}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (w *intelWatcher) close(channel string) {
+	if f, ok := w.files[channel]; ok {
+		f.file.Close()
+		delete(w.files, channel)
+	}
+}
+
+func (w *intelWatcher) closeAll() {
+	for channel := range w.files {
+		w.close(channel)
+	}
+}
+
+// parseChannels splits the comma separated channel setting.
+func parseChannels(setting string) map[string]bool {
+	channels := make(map[string]bool)
+	for _, channel := range strings.Split(setting, ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			channels[channel] = true
+		}
+	}
+	return channels
+}
+
+// onIntelLine parses an intel message and hands it to every monitor. Names are
+// matched against the systems around the monitored characters.
+func (s *Service) onIntelLine(line intelLine) {
+	if s.universe.Empty() {
+		return
+	}
+	rangeJumps := s.configSvc.GetIntelRangeJumps()
+	near := make(map[int64]int)
+	for charID := range s.monitors {
+		current, ok := s.universe.SystemByName(s.locationSvc.System(charID))
+		if !ok {
+			continue
+		}
+		for id, jumps := range s.universe.WithinJumps(current.ID, rangeJumps) {
+			if known, ok := near[id]; !ok || jumps < known {
+				near[id] = jumps
+			}
+		}
+	}
+	report := s.intelParser.Parse(line.Text, near)
+	if len(report.Systems) == 0 {
+		return
+	}
+	logger.Sugar.Debugf("Intel in %s: %s (%d systems)", line.Channel, line.Text, len(report.Systems))
+	for _, monitor := range s.monitors {
+		monitor.onIntel(line, report, rangeJumps)
+	}
+}
+
+// onIntel alerts if a report concerns a system within range of the character.
+// Closer reports repeat the sound more often.
+func (m *characterMonitor) onIntel(line intelLine, report intel.Report, rangeJumps int) {
+	settings, exists := m.subSvc.GetSettings(m.charID)
+	if !exists || !settings.IntelReports || report.Status {
+		return
+	}
+	current, ok := m.universe.SystemByName(m.locationSvc.System(m.charID))
+	if !ok {
+		return
+	}
+	var nearest *sde.System
+	distance := -1
+	for _, system := range report.Systems {
+		if jumps := m.universe.Jumps(current.ID, system.ID); jumps >= 0 && (distance < 0 || jumps < distance) {
+			nearest, distance = system, jumps
+		}
+	}
+	if nearest == nil || distance > rangeJumps {
+		return
+	}
+
+	where := fmt.Sprintf("%s (%s)", nearest.Name, jumpsAway(distance))
+	switch {
+	case report.Hostile():
+		message := fmt.Sprintf("Hostiles in %s", where)
+		if len(report.Ships) > 0 {
+			message += ": " + strings.Join(report.Ships, ", ")
+		}
+		message += fmt.Sprintf(". %s in %s: %s", line.Speaker, line.Channel, line.Text)
+		logger.Sugar.Infof("[%d] %s", m.charID, message)
+		m.notifSvc.NotifyRepeat("EVE Notify - Intel", m.message(message), intelRepeats(distance, rangeJumps))
+	case report.Clear:
+		m.notifSvc.Notify("EVE Notify - Intel", m.message(fmt.Sprintf("%s is clear.", where)), false)
+	case report.NoVisual:
+		m.notifSvc.Notify("EVE Notify - Intel", m.message(fmt.Sprintf("No visual on hostiles in %s.", where)), false)
+	}
+}
+
+// intelRepeats is how often the sound plays for hostiles some jumps away: three
+// times next door, twice within half the range and once further out.
+func intelRepeats(distance, rangeJumps int) int {
+	switch {
+	case distance <= 1:
+		return 3
+	case distance*2 <= rangeJumps:
+		return 2
+	default:
+		return 1
+	}
+}
+
+func jumpsAway(jumps int) string {
+	switch jumps {
+	case 0:
+		return "your system"
+	case 1:
+		return "1 jump"
+	default:
+		return fmt.Sprintf("%d jumps", jumps)
+	}
+}
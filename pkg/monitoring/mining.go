@@ -0,0 +1,77 @@
+package monitoring
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/mining"
+)
+
+var (
+	// minedRegex captures the units and ore of a "(mining)" line, e.g.
+	// "You mined 285 units of Veldspar". Critical successes read "You mined an
+	// additional 142 units of Veldspar". Gas is "harvested" instead.
+	minedRegex = regexp.MustCompile(`(?i)you (?:mined|harvested) (an additional )?([\d,. ]+?) units? of (.+?)(?:\.|$)`)
+	// residueRegex captures the units an asteroid lost as residue.
+	residueRegex = regexp.MustCompile(`(?i)additional ([\d,. ]+?) units? (?:were )?depleted from (?:the )?asteroid`)
+)
+
+// parseMining reads a mining cycle from the text of a "(mining)" gamelog line.
+func parseMining(line gamelogLine) (mining.Cycle, bool) {
+	text := markupRegex.ReplaceAllString(line.Text, "")
+	matches := minedRegex.FindStringSubmatch(text)
+	if matches == nil {
+		return mining.Cycle{}, false
+	}
+	units, ok := parseUnits(matches[2])
+	if !ok {
+		return mining.Cycle{}, false
+	}
+	cycle := mining.Cycle{
+		Time:     line.Time,
+		Ore:      strings.TrimSpace(matches[3]),
+		Units:    units,
+		Critical: matches[1] != "" || strings.Contains(strings.ToLower(text), "critical"),
+	}
+	if residue := residueRegex.FindStringSubmatch(text); residue != nil {
+		cycle.Residue, _ = parseUnits(residue[1])
+	}
+	return cycle, true
+}
+
+// predictHoldFull adds a cycle to the character's ore hold and warns once the
+// hold is expected to fill within the configured time.
+func (m *characterMonitor) predictHoldFull(cycle mining.Cycle, alert bool) {
+	volume, ok := m.miningSvc.HoldVolume(cycle.Ore)
+	if !ok {
+		return
+	}
+	warnBefore := time.Duration(m.configSvc.GetCargoWarnMinutes()) * time.Minute
+	state, warn := m.miningSvc.AddToHold(m.charID, cycle.Time, volume*float64(cycle.Units), warnBefore)
+	if !warn || !alert {
+		return
+	}
+	until := "less than a minute"
+	if minutes := int(state.UntilFull.Round(time.Minute).Minutes()); minutes >= 1 {
+		until = fmt.Sprintf("about %d min", minutes)
+	}
+	message := fmt.Sprintf("Ore hold full in %s (%.0f of %.0f m³).", until, state.Filled, state.Capacity)
+	logger.Sugar.Infof("[%d] %s", m.charID, message)
+	m.notifSvc.Notify("EVE Notify - Mining", m.message(message), true)
+}
+
+// parseUnits reads a unit count written with any thousands separator.
+func parseUnits(text string) (int64, bool) {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, text)
+	units, err := strconv.ParseInt(digits, 10, 64)
+	return units, err == nil
+}
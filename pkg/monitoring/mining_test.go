@@ -0,0 +1,85 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/mining"
+)
+
+// gamelog parses a raw gamelog line, failing the test if it is malformed.
+func gamelog(t *testing.T, raw string) gamelogLine {
+	t.Helper()
+	line, ok := parseGamelogLine(raw)
+	if !ok {
+		t.Fatalf("parseGamelogLine(%q) failed", raw)
+	}
+	return line
+}
+
+func TestParseMining(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 5, 0, time.UTC)
+	tests := []struct {
+		name string
+		raw  string
+		want mining.Cycle
+		ok   bool
+	}{
+		{
+			"client markup",
+			"[ 2024.05.01 12:00:05 ] (mining) You mined <color=#ff8dc169>285</color><color=#ff9a9a9a> units of </color><color=#ff8dc169><font size=12><a href=showinfo:1230>Veldspar</a></font></color>",
+			mining.Cycle{Time: at, Ore: "Veldspar", Units: 285},
+			true,
+		},
+		{
+			"comma separator and residue",
+			"[ 2024.05.01 12:00:05 ] (mining) You mined 1,200 units of Scordite. An additional 300 units were depleted from the asteroid as residue.",
+			mining.Cycle{Time: at, Ore: "Scordite", Units: 1200, Residue: 300},
+			true,
+		},
+		{
+			"dot separator",
+			"[ 2024.05.01 12:00:05 ] (mining) You mined 1.200 units of Scordite",
+			mining.Cycle{Time: at, Ore: "Scordite", Units: 1200},
+			true,
+		},
+		{
+			"space separator",
+			"[ 2024.05.01 12:00:05 ] (mining) You mined 12 500 units of Compressed Veldspar",
+			mining.Cycle{Time: at, Ore: "Compressed Veldspar", Units: 12500},
+			true,
+		},
+		{
+			"critical success",
+			"[ 2024.05.01 12:00:05 ] (mining) Critical mining success! You mined an additional <color=#ff8dc169>142</color> units of <a href=showinfo:1230>Veldspar</a>.",
+			mining.Cycle{Time: at, Ore: "Veldspar", Units: 142, Critical: true},
+			true,
+		},
+		{
+			"gas",
+			"[ 2024.05.01 12:00:05 ] (mining) You harvested 20 units of Fullerite-C50",
+			mining.Cycle{Time: at, Ore: "Fullerite-C50", Units: 20},
+			true,
+		},
+		{
+			"single unit",
+			"[ 2024.05.01 12:00:05 ] (mining) You mined 1 unit of Mercoxit",
+			mining.Cycle{Time: at, Ore: "Mercoxit", Units: 1},
+			true,
+		},
+		{
+			"other notice",
+			"[ 2024.05.01 12:00:05 ] (notify) Ship's cargo hold is full",
+			mining.Cycle{},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseMining(gamelog(t, tt.raw))
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("parseMining = %+v, %t; want %+v, %t", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package monitoring
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+const (
+	// recentIntervals is how many gaps between cycles the cadence is learned from.
+	recentIntervals = 10
+	// idleFactor is how many times the longest recent gap may pass without a
+	// cycle before the lasers count as stopped.
+	idleFactor = 1.5
+	// minIdleAfter keeps a burst of short gaps from raising early alerts.
+	minIdleAfter = 30 * time.Second
+	// miningRecently is how long after the last cycle notices still count as
+	// being about mining.
+	miningRecently = 10 * time.Minute
+)
+
+var (
+	// asteroidDepletedRegex matches the notice that the targeted asteroid is gone.
+	asteroidDepletedRegex = regexp.MustCompile(`(?i)asteroid.*(depleted|no longer)|depleted.*asteroid`)
+	// moduleDeactivatedRegex matches a module switching itself off.
+	moduleDeactivatedRegex = regexp.MustCompile(`(?i)\bdeactivat(es|ed|ing)\b`)
+)
+
+// miningIdleTracker learns how often a character's mining cycles complete and
+// notices when they stop. It is shared between the monitor loop and the
+// gamelog worker, so it is locked.
+type miningIdleTracker struct {
+	mu        sync.Mutex
+	lastCycle time.Time
+	intervals []time.Duration
+	notified  bool
+}
+
+// onCycle records a completed mining cycle.
+func (t *miningIdleTracker) onCycle(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.lastCycle.IsZero() {
+		// A long pause is a new mining run, not part of the cadence.
+		if gap := at.Sub(t.lastCycle); gap > 0 && gap < miningRecently {
+			t.intervals = append(t.intervals, gap)
+			if len(t.intervals) > recentIntervals {
+				t.intervals = t.intervals[len(t.intervals)-recentIntervals:]
+			}
+		}
+	}
+	if at.After(t.lastCycle) {
+		t.lastCycle = at
+	}
+	t.notified = false
+}
+
+// stop ends the current mining run, e.g. when the hold is full, so the
+// silence that follows is not reported.
+func (t *miningIdleTracker) stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastCycle = time.Time{}
+	t.intervals = nil
+	t.notified = false
+}
+
+// mining reports whether a cycle completed recently.
+func (t *miningIdleTracker) mining(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !t.lastCycle.IsZero() && now.Sub(t.lastCycle) < miningRecently
+}
+
+// checkIdle reports how long no cycle has arrived, once per silence, if that
+// is longer than the learned cadence allows.
+func (t *miningIdleTracker) checkIdle(now time.Time) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Two gaps are needed to tell the cadence of several lasers apart.
+	if t.notified || t.lastCycle.IsZero() || len(t.intervals) < 2 {
+		return 0, false
+	}
+	var longest time.Duration
+	for _, interval := range t.intervals {
+		longest = max(longest, interval)
+	}
+	silence := now.Sub(t.lastCycle)
+	if silence < max(time.Duration(float64(longest)*idleFactor), minIdleAfter) {
+		return 0, false
+	}
+	t.notified = true
+	return silence, true
+}
+
+// onMiningNotice reports asteroid depletion and modules turning off while the
+// character is mining.
+func (m *characterMonitor) onMiningNotice(line gamelogLine, alert bool) {
+	if line.Channel != "notify" || !m.miningIdle.mining(line.Time) {
+		return
+	}
+	text := markupRegex.ReplaceAllString(line.Text, "")
+	var message string
+	switch {
+	case asteroidDepletedRegex.MatchString(text):
+		message = "Asteroid depleted: " + text
+	case moduleDeactivatedRegex.MatchString(text):
+		message = "Module deactivated: " + text
+	default:
+		return
+	}
+	logger.Sugar.Infof("[%d] %s", m.charID, message)
+	if alert {
+		m.notifSvc.Notify("EVE Notify - Mining", m.message(message), true)
+	}
+}
+
+// checkMiningIdle alerts when the expected mining cycles stop arriving.
+func (m *characterMonitor) checkMiningIdle(now time.Time) {
+	silence, idle := m.miningIdle.checkIdle(now)
+	if !idle {
+		return
+	}
+	settings, exists := m.subSvc.GetSettings(m.charID)
+	if !exists || !settings.MiningIdle {
+		return
+	}
+	message := fmt.Sprintf("No mining cycle for %s; the lasers may have stopped.", silence.Round(time.Second))
+	logger.Sugar.Infof("[%d] %s", m.charID, message)
+	m.notifSvc.Notify("EVE Notify - Mining", m.message(message), true)
+}
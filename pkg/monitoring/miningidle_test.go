@@ -0,0 +1,58 @@
+package monitoring
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMiningNoticeRegexes(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		depleted    bool
+		deactivated bool
+	}{
+		{"asteroid depleted", "The asteroid has been depleted.", true, false},
+		{"ore link", `<a href=showinfo:1230>Veldspar</a> asteroid depleted`, true, false},
+		{"asteroid gone", "Your Miner II deactivates as the asteroid is no longer there.", true, true},
+		{"target destroyed", `Your <a href=showinfo:482>Miner II</a> deactivates due to the destruction of the target.`, false, true},
+		{"hold full", "Modulated Strip Miner II deactivated because your cargo hold is full", false, true},
+		{"cargo notice", "Ship's cargo hold is full", false, false},
+		{"not a verb", "Mining laser deactivation delay", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := markupRegex.ReplaceAllString(tt.text, "")
+			if got := asteroidDepletedRegex.MatchString(text); got != tt.depleted {
+				t.Errorf("depleted in %q = %t, want %t", text, got, tt.depleted)
+			}
+			if got := moduleDeactivatedRegex.MatchString(text); got != tt.deactivated {
+				t.Errorf("deactivated in %q = %t, want %t", text, got, tt.deactivated)
+			}
+		})
+	}
+}
+
+func TestMiningIdleTracker(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var tracker miningIdleTracker
+	for i := range 3 {
+		tracker.onCycle(start.Add(time.Duration(i) * time.Minute))
+	}
+	last := start.Add(2 * time.Minute)
+
+	if _, idle := tracker.checkIdle(last.Add(80 * time.Second)); idle {
+		t.Error("idle within one and a half cycles")
+	}
+	if silence, idle := tracker.checkIdle(last.Add(100 * time.Second)); !idle || silence != 100*time.Second {
+		t.Errorf("checkIdle after 100 s = %s, %t; want idle", silence, idle)
+	}
+	if _, idle := tracker.checkIdle(last.Add(200 * time.Second)); idle {
+		t.Error("the same silence was reported twice")
+	}
+
+	tracker.stop()
+	if _, idle := tracker.checkIdle(last.Add(time.Hour)); idle || tracker.mining(last) {
+		t.Error("idle or mining after the run was stopped")
+	}
+}
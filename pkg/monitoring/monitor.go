@@ -5,40 +5,75 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sync"
 	"time"
 
+	"github.com/FabricSoul/eve-notify/pkg/bounty"
+	"github.com/FabricSoul/eve-notify/pkg/character"
+	"github.com/FabricSoul/eve-notify/pkg/combat"
 	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/location"
 	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/mining"
 	"github.com/FabricSoul/eve-notify/pkg/notification"
+	"github.com/FabricSoul/eve-notify/pkg/route"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+	"github.com/FabricSoul/eve-notify/pkg/serverstatus"
 	"github.com/FabricSoul/eve-notify/pkg/subscription"
 )
 
 // characterMonitor listens for log file changes for a single character.
 type characterMonitor struct {
-	charID    int64
-	configSvc *config.Service
-	subSvc    *subscription.Service
-	notifSvc *notification.Service
-	ctx       context.Context
-	cancel    context.CancelFunc
+	charID      int64
+	configSvc   *config.Service
+	subSvc      *subscription.Service
+	notifSvc    *notification.Service
+	statusSvc   *serverstatus.Service
+	locationSvc *location.Service
+	universe    *sde.Universe
+	routeSvc    *route.Service
+	miningSvc   *mining.Service
+	bountySvc   *bounty.Service
+	combatSvc   *combat.Service
+	index       *character.Index
+	ctx         context.Context
+	cancel      context.CancelFunc
 
 	// Active log file paths and their cancel functions
-	activeGamelogFile    string
-	cancelActiveGamelog  context.CancelFunc
+	activeGamelogFile     string
+	cancelActiveGamelog   context.CancelFunc
+	activeLocalChatFile   string
+	cancelActiveLocalChat context.CancelFunc
 	// ... add other logs like Chatlogs here ...
+
+	session    sessionTracker
+	miningIdle miningIdleTracker
+
+	// charName is the listener name from the latest gamelog header, and
+	// started when that session began.
+	charName string
+	started  time.Time
+	mu       sync.RWMutex
 }
 
-func newCharacterMonitor(ctx context.Context, charID int64, cfg *config.Service, sub *subscription.Service, notifi *notification.Service) *characterMonitor {
+func newCharacterMonitor(ctx context.Context, charID int64, cfg *config.Service, sub *subscription.Service, notifi *notification.Service, status *serverstatus.Service, loc *location.Service, universe *sde.Universe, routes *route.Service, miningSvc *mining.Service, bountySvc *bounty.Service, combatSvc *combat.Service, index *character.Index) *characterMonitor {
 	// Create a new context for this monitor that is a child of the service's context.
 	monitorCtx, monitorCancel := context.WithCancel(ctx)
 	return &characterMonitor{
-		charID:    charID,
-		configSvc: cfg,
-		subSvc:    sub,
-		notifSvc: notifi,
-		ctx:       monitorCtx,
-		cancel:    monitorCancel,
+		charID:      charID,
+		configSvc:   cfg,
+		subSvc:      sub,
+		notifSvc:    notifi,
+		statusSvc:   status,
+		locationSvc: loc,
+		universe:    universe,
+		routeSvc:    routes,
+		miningSvc:   miningSvc,
+		bountySvc:   bountySvc,
+		combatSvc:   combatSvc,
+		index:       index,
+		ctx:         monitorCtx,
+		cancel:      monitorCancel,
 	}
 }
 
@@ -55,6 +90,18 @@ func (m *characterMonitor) run() {
 		select {
 		case <-ticker.C:
 			m.checkForNewLogs()
+			if m.statusSvc.DowntimePaused(time.Now()) {
+				// Every client is dropped at downtime; treat the session as
+				// ended so neither the disconnect nor the silence is reported.
+				m.session.pause()
+				m.miningIdle.stop()
+				m.finishBounties()
+				continue
+			}
+			idleThreshold := time.Duration(m.configSvc.GetClientIdleMinutes()) * time.Minute
+			m.notifySession(m.session.checkIdle(idleThreshold, time.Now()))
+			m.checkRouteStuck(time.Now())
+			m.checkMiningIdle(time.Now())
 		case <-m.ctx.Done():
 			logger.Sugar.Debugf("[%d] Monitor run loop stopping.", m.charID)
 			m.stopAllWorkers()
@@ -71,77 +118,119 @@ func (m *characterMonitor) stopAllWorkers() {
 	if m.cancelActiveGamelog != nil {
 		m.cancelActiveGamelog()
 	}
+	if m.cancelActiveLocalChat != nil {
+		m.cancelActiveLocalChat()
+	}
 }
 
 // checkForNewLogs finds the latest log files and starts/stops workers as needed.
 func (m *characterMonitor) checkForNewLogs() {
-	settings, exists := m.subSvc.GetSettings(m.charID)
-	if !exists {
+	if _, exists := m.subSvc.GetSettings(m.charID); !exists {
 		// Should not happen if logic is correct, but a good safeguard.
 		m.stop()
 		return
 	}
 
-	// --- CORRECTED GAMELOG LOGIC ---
-	// First, determine if the gamelog worker should be running at all.
-	isGamelogMonitoringNeeded := settings.MiningStorageFull || settings.ManualAutopilot // Add future Gamelog settings here
+	// The gamelog worker always runs: besides the alerts it checks for, its
+	// jump lines keep the character's location current.
+	latestGamelog := m.findLatestLog(character.Gamelog, "")
 
-	if isGamelogMonitoringNeeded {
-		// If it should be running, find the latest log file.
-		logPath := m.configSvc.GetLogPath()
-		gamelogDir := filepath.Join(logPath, "Gamelogs")
-		latestGamelog := m.findLatestLog(gamelogDir, fmt.Sprintf(`^\d{8}_\d{6}_%d\.txt$`, m.charID))
+	// Only act if we found a file AND it's a different one than we're currently watching.
+	if latestGamelog != "" && latestGamelog != m.activeGamelogFile {
+		logger.Sugar.Infof("[%d] New gamelog detected for monitoring: %s", m.charID, filepath.Base(latestGamelog))
 
-		// Only act if we found a file AND it's a different one than we're currently watching.
-		if latestGamelog != "" && latestGamelog != m.activeGamelogFile {
-			logger.Sugar.Infof("[%d] New gamelog detected for monitoring: %s", m.charID, filepath.Base(latestGamelog))
-
-			// Stop the old worker if it's running.
-			if m.cancelActiveGamelog != nil {
-				m.cancelActiveGamelog()
-			}
-
-			// Start the new, generalized worker.
-			workerCtx, workerCancel := context.WithCancel(m.ctx)
-			m.activeGamelogFile = latestGamelog
-			m.cancelActiveGamelog = workerCancel
-			go m.gamelogWorker(workerCtx, latestGamelog, settings)
-		}
-	} else {
-		// If no Gamelog monitoring is needed, ensure the worker is stopped.
+		// Stop the old worker if it's running.
 		if m.cancelActiveGamelog != nil {
-			logger.Sugar.Infof("[%d] Disabling gamelog worker as no relevant notifications are active.", m.charID)
 			m.cancelActiveGamelog()
-			m.cancelActiveGamelog = nil
-			m.activeGamelogFile = ""
 		}
+
+		m.startSession(latestGamelog)
+
+		// Start the new, generalized worker.
+		workerCtx, workerCancel := context.WithCancel(m.ctx)
+		m.activeGamelogFile = latestGamelog
+		m.cancelActiveGamelog = workerCancel
+		go m.gamelogWorker(workerCtx, latestGamelog)
 	}
 
-	// Future: Add checks for Chatlogs here in a similar `if/else` block.
+	// Local chat announces every system change, including those without a
+	// jump line such as logging in, docking up after a wormhole or being podded.
+	latestLocal := m.findLatestLog(character.Chatlog, "Local")
+	if latestLocal != "" && latestLocal != m.activeLocalChatFile {
+		if m.cancelActiveLocalChat != nil {
+			m.cancelActiveLocalChat()
+		}
+		workerCtx, workerCancel := context.WithCancel(m.ctx)
+		m.activeLocalChatFile = latestLocal
+		m.cancelActiveLocalChat = workerCancel
+		go m.localChatWorker(workerCtx, latestLocal)
+	}
 }
 
-// findLatestLog scans a directory for files matching a pattern and returns the path of the most recent one.
-func (m *characterMonitor) findLatestLog(dir, pattern string) string {
-	files, err := os.ReadDir(dir)
+// startSession reads the header of a newly watched gamelog to learn the
+// character's name and whether the client just logged in.
+func (m *characterMonitor) startSession(path string) {
+	header, err := character.ReadLogHeader(path)
 	if err != nil {
-		logger.Sugar.Errorf("Failed to read directory %s: %v", dir, err)
-		return ""
+		logger.Sugar.Warnf("[%d] Could not read gamelog header: %v", m.charID, err)
+	} else {
+		m.mu.Lock()
+		m.charName = header.Listener
+		m.mu.Unlock()
 	}
 
-	re := regexp.MustCompile(pattern)
-	var latestFile string
-	var latestTime time.Time
+	modTime := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+	// A new gamelog is a new session; the previous one is over.
+	m.finishBounties()
+	m.mu.Lock()
+	m.started = modTime
+	if header != nil && !header.SessionStarted.IsZero() {
+		m.started = header.SessionStarted
+	}
+	m.mu.Unlock()
+	m.notifySession(m.session.onNewLog(header, modTime, time.Now()))
+}
 
-	for _, file := range files {
-		if !file.IsDir() && re.MatchString(file.Name()) {
-			info, err := file.Info()
-			if err != nil { continue }
+// sessionStarted returns when the watched gamelog's session began.
+func (m *characterMonitor) sessionStarted() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.started
+}
 
-			if info.ModTime().After(latestTime) {
-				latestTime = info.ModTime()
-				latestFile = filepath.Join(dir, file.Name())
-			}
-		}
+// message formats an alert for this character with the user's message template.
+func (m *characterMonitor) message(text string) string {
+	return notification.FormatMessage(m.configSvc.GetMessageTemplate(), notification.MessageData{
+		Character: m.displayName(),
+		System:    m.locationSvc.System(m.charID),
+		Message:   text,
+	})
+}
+
+// displayName returns the character's name if known, or a placeholder with its ID.
+func (m *characterMonitor) displayName() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.charName != "" {
+		return m.charName
+	}
+	return fmt.Sprintf("Character %d", m.charID)
+}
+
+// findLatestLog updates the log index and returns the path of the character's
+// most recent log of the given type, or "" if there is none.
+func (m *characterMonitor) findLatestLog(logType character.LogType, channel string) string {
+	logPath := m.configSvc.GetLogPath()
+	if err := m.index.Update(logPath); err != nil {
+		logger.Sugar.Errorf("[%d] Failed to update log index: %v", m.charID, err)
+		return ""
+	}
+	latest, ok := m.index.Latest(logType, m.charID, channel)
+	if !ok {
+		return ""
 	}
-	return latestFile
+	return latest.Path(logPath)
 }
@@ -0,0 +1,72 @@
+package monitoring
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/route"
+)
+
+var (
+	// destinationSetRegex captures the system of an autopilot route notice,
+	// e.g. "Autopilot destination set to Jita". The wording has varied
+	// between client versions, so both "route" and "destination" are accepted.
+	destinationSetRegex = regexp.MustCompile(`(?i)(?:route|destination) (?:set|changed) to (.+?)\.?$`)
+	// destinationReachedRegex matches the notice that the autopilot arrived.
+	destinationReachedRegex = regexp.MustCompile(`(?i)destination (?:has been )?reached|reached your destination`)
+)
+
+// onJump advances the character's route, notifying on arrival. It returns the
+// route text to append to a jump notice, if the character has a route.
+func (m *characterMonitor) onJump(to string, at time.Time, alert bool) string {
+	progress, ok := m.routeSvc.Jumped(m.charID, to, at)
+	if !ok {
+		return ""
+	}
+	if progress.Jumps == 0 {
+		m.notifyArrival(progress, alert)
+		return ""
+	}
+	return progress.Summary()
+}
+
+// onRouteNotice handles the client's autopilot notices in a gamelog line.
+func (m *characterMonitor) onRouteNotice(text string, alert bool) {
+	text = markupRegex.ReplaceAllString(text, "")
+	if matches := destinationSetRegex.FindStringSubmatch(text); matches != nil {
+		if err := m.routeSvc.SetDestination(m.charID, matches[1]); err != nil {
+			logger.Sugar.Warnf("[%d] Could not follow autopilot route: %v", m.charID, err)
+		}
+		return
+	}
+	if destinationReachedRegex.MatchString(text) {
+		if progress, ok := m.routeSvc.Arrived(m.charID, m.locationSvc.System(m.charID)); ok {
+			m.notifyArrival(progress, alert)
+		}
+	}
+}
+
+func (m *characterMonitor) notifyArrival(progress route.Progress, alert bool) {
+	logger.Sugar.Infof("[%d] Arrived at %s after %d jumps.", m.charID, progress.Destination, progress.JumpsMade)
+	if alert {
+		message := fmt.Sprintf("Arrived at %s after %d jumps.", progress.Destination, progress.JumpsMade)
+		m.notifSvc.Notify("EVE Notify - Autopilot", m.message(message), true)
+	}
+}
+
+// checkRouteStuck alerts when a character on a route stopped jumping.
+func (m *characterMonitor) checkRouteStuck(now time.Time) {
+	settings, exists := m.subSvc.GetSettings(m.charID)
+	if !exists || !settings.AutopilotRoute {
+		return
+	}
+	progress, since, stuck := m.routeSvc.CheckStuck(m.charID, m.locationSvc.System(m.charID), now)
+	if !stuck {
+		return
+	}
+	message := fmt.Sprintf("No jump for %s. %s", since.Round(time.Second), progress.Summary())
+	logger.Sugar.Infof("[%d] %s", m.charID, message)
+	m.notifSvc.Notify("EVE Notify - Autopilot", m.message(message), true)
+}
@@ -0,0 +1,69 @@
+package monitoring
+
+import (
+	"os"
+	"testing"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	sync := logger.Init()
+	code := m.Run()
+	sync()
+	os.Exit(code)
+}
+
+func TestParseJump(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		from, to string
+		ok       bool
+	}{
+		{"plain", "Jumping from Jita to Perimeter", "Jita", "Perimeter", true},
+		{"showinfo links", `Jumping from <a href="showinfo:5//30000142">Jita</a> to <a href="showinfo:5//30000144">Perimeter</a>`, "Jita", "Perimeter", true},
+		{"null-sec names", "Jumping from 1DQ1-A to 8WA-Z6", "1DQ1-A", "8WA-Z6", true},
+		{"other notice", "Undocking from Jita IV - Moon 4 - Caldari Navy Assembly Plant to Jita solar system.", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, ok := parseJump(tt.text)
+			if from != tt.from || to != tt.to || ok != tt.ok {
+				t.Errorf("parseJump(%q) = %q, %q, %t; want %q, %q, %t", tt.text, from, to, ok, tt.from, tt.to, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDestinationRegexes(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		destination string // Empty if the line does not set a destination.
+		reached     bool
+	}{
+		{"destination set", "Autopilot destination set to Amarr.", "Amarr", false},
+		{"route changed", "Autopilot route changed to Dodixie", "Dodixie", false},
+		{"showinfo link", `Autopilot destination set to <a href="showinfo:5//30002187">Amarr</a>.`, "Amarr", false},
+		{"null-sec name", "Autopilot destination set to 1DQ1-A", "1DQ1-A", false},
+		{"destination reached", "Destination has been reached.", "", true},
+		{"reached your destination", "You have reached your destination.", "", true},
+		{"other notice", "Jumping from Jita to Perimeter", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := markupRegex.ReplaceAllString(tt.text, "")
+			var destination string
+			if matches := destinationSetRegex.FindStringSubmatch(text); matches != nil {
+				destination = matches[1]
+			}
+			if destination != tt.destination {
+				t.Errorf("destination of %q = %q, want %q", tt.text, destination, tt.destination)
+			}
+			if reached := destinationReachedRegex.MatchString(text); reached != tt.reached {
+				t.Errorf("reached in %q = %t, want %t", tt.text, reached, tt.reached)
+			}
+		})
+	}
+}
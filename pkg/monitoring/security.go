@@ -0,0 +1,84 @@
+package monitoring
+
+import (
+	"fmt"
+
+	"github.com/FabricSoul/eve-notify/pkg/location"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+)
+
+// Wormhole systems (J-space, Thera and the like) have IDs in this range.
+const (
+	firstWormholeSystemID = 31000000
+	lastWormholeSystemID  = 31999999
+)
+
+// pochvenRegion is the name of the Triglavian region, which is -1.0 space
+// reached through filaments and conduits rather than stargates.
+const pochvenRegion = "Pochven"
+
+// spaceKind tells the special parts of New Eden apart from ordinary k-space.
+type spaceKind string
+
+const (
+	knownSpace    spaceKind = ""
+	pochvenSpace  spaceKind = "Pochven"
+	wormholeSpace spaceKind = "wormhole space"
+)
+
+func kindOf(universe *sde.Universe, system *sde.System) spaceKind {
+	if system.ID >= firstWormholeSystemID && system.ID <= lastWormholeSystemID {
+		return wormholeSpace
+	}
+	if region, ok := universe.Region(system.RegionID); ok && region.Name == pochvenRegion {
+		return pochvenSpace
+	}
+	return knownSpace
+}
+
+// securityDropMessage describes moving from one system into another if the
+// destination is Pochven or wormhole space, or if security falls from at least
+// threshold to below it. It returns false if there is nothing to report or
+// either system is unknown.
+func securityDropMessage(universe *sde.Universe, from, to string, threshold float64) (string, bool) {
+	origin, ok := universe.SystemByName(from)
+	if !ok {
+		return "", false
+	}
+	destination, ok := universe.SystemByName(to)
+	if !ok {
+		return "", false
+	}
+
+	if kind := kindOf(universe, destination); kind != knownSpace {
+		if kindOf(universe, origin) == kind {
+			return "", false
+		}
+		return fmt.Sprintf("Entered %s (%s) from %s.", destination.Name, kind, origin.Name), true
+	}
+	if destination.DisplaySecurity() >= threshold || origin.DisplaySecurity() < threshold {
+		return "", false
+	}
+	return fmt.Sprintf("Entered %s (%.1f %s) from %s (%.1f).", destination.Name, destination.DisplaySecurity(),
+		destination.Class(), origin.Name, origin.DisplaySecurity()), true
+}
+
+// onLocationChanged alerts on dropping into more dangerous space. It is called
+// for system changes seen in either the gamelog or the Local chatlog.
+func (m *characterMonitor) onLocationChanged(loc location.Location) {
+	settings, exists := m.subSvc.GetSettings(m.charID)
+	if !exists || !settings.SecurityDrop || loc.Previous == "" {
+		return
+	}
+	if m.universe.Empty() {
+		logger.Sugar.Debugf("[%d] No universe data; skipping security check for %s.", m.charID, loc.System)
+		return
+	}
+	message, ok := securityDropMessage(m.universe, loc.Previous, loc.System, settings.SecurityThresholdOrDefault())
+	if !ok {
+		return
+	}
+	logger.Sugar.Infof("[%d] %s", m.charID, message)
+	m.notifSvc.Notify("EVE Notify - Security", m.message(message), true)
+}
@@ -4,32 +4,64 @@ import (
 	"context"
 	"sync"
 
+	"github.com/FabricSoul/eve-notify/pkg/bounty"
+	"github.com/FabricSoul/eve-notify/pkg/character"
+	"github.com/FabricSoul/eve-notify/pkg/combat"
 	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/intel"
+	"github.com/FabricSoul/eve-notify/pkg/location"
 	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/mining"
 	"github.com/FabricSoul/eve-notify/pkg/notification"
+	"github.com/FabricSoul/eve-notify/pkg/profile"
+	"github.com/FabricSoul/eve-notify/pkg/route"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+	"github.com/FabricSoul/eve-notify/pkg/serverstatus"
 	"github.com/FabricSoul/eve-notify/pkg/subscription"
 )
 
 // Service is the main monitoring controller.
 type Service struct {
-	configSvc *config.Service
-	subSvc    *subscription.Service
-	notifSvc  *notification.Service
-	monitors  map[int64]*characterMonitor
-	ctx       context.Context
-	cancel    context.CancelFunc
-	wg        sync.WaitGroup
+	configSvc   *config.Service
+	subSvc      *subscription.Service
+	notifSvc    *notification.Service
+	statusSvc   *serverstatus.Service
+	locationSvc *location.Service
+	universe    *sde.Universe
+	routeSvc    *route.Service
+	miningSvc   *mining.Service
+	bountySvc   *bounty.Service
+	combatSvc   *combat.Service
+	monitors    map[int64]*characterMonitor
+	index       *character.Index
+	autoSub     *autoSubscriber
+	intel       *intelWatcher
+	intelParser *intel.Parser
+	ctx         context.Context
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
 }
 
-func NewService(cfg *config.Service, sub *subscription.Service, notif *notification.Service) *Service {
+func NewService(cfg *config.Service, sub *subscription.Service, prof *profile.Service, notif *notification.Service, status *serverstatus.Service, loc *location.Service, universe *sde.Universe, routes *route.Service, miningSvc *mining.Service, bountySvc *bounty.Service, combatSvc *combat.Service, index *character.Index) *Service {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Service{
-		configSvc: cfg,
-		subSvc:    sub,
-		notifSvc:  notif,
-		monitors:  make(map[int64]*characterMonitor),
-		ctx:       ctx,
-		cancel:    cancel,
+		configSvc:   cfg,
+		subSvc:      sub,
+		notifSvc:    notif,
+		statusSvc:   status,
+		locationSvc: loc,
+		universe:    universe,
+		routeSvc:    routes,
+		miningSvc:   miningSvc,
+		bountySvc:   bountySvc,
+		combatSvc:   combatSvc,
+		monitors:    make(map[int64]*characterMonitor),
+		index:       index,
+		autoSub:     newAutoSubscriber(cfg, sub, prof, notif, index),
+		intel:       newIntelWatcher(cfg, index),
+		intelParser: intel.NewParser(universe),
+		ctx:         ctx,
+		cancel:      cancel,
 	}
 }
 
@@ -39,12 +71,26 @@ func (s *Service) Start() {
 	s.wg.Add(1)
 	defer s.wg.Done()
 
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.autoSub.run(s.ctx)
+	}()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.intel.run(s.ctx)
+	}()
+
 	for {
 		select {
 		case charID := <-s.subSvc.Subscribed:
 			s.startMonitor(charID)
 		case charID := <-s.subSvc.Unsubscribed:
 			s.stopMonitor(charID)
+		case line := <-s.intel.lines:
+			s.onIntelLine(line)
 		case <-s.ctx.Done():
 			logger.Sugar.Infoln("Monitoring service shutting down.")
 			return
@@ -55,8 +101,8 @@ func (s *Service) Start() {
 // Stop gracefully shuts down all active monitors.
 func (s *Service) Stop() {
 	logger.Sugar.Infoln("Stopping monitoring service...")
-	s.cancel() // Signal all goroutines to stop.
-	s.wg.Wait()  // Wait for the main loop and all monitors to finish.
+	s.cancel()  // Signal all goroutines to stop.
+	s.wg.Wait() // Wait for the main loop and all monitors to finish.
 	logger.Sugar.Infoln("Monitoring service stopped.")
 }
 
@@ -66,7 +112,7 @@ func (s *Service) startMonitor(charID int64) {
 		return
 	}
 	logger.Sugar.Infof("Starting monitor for character %d.", charID)
-	monitor := newCharacterMonitor(s.ctx, charID, s.configSvc, s.subSvc, s.notifSvc)
+	monitor := newCharacterMonitor(s.ctx, charID, s.configSvc, s.subSvc, s.notifSvc, s.statusSvc, s.locationSvc, s.universe, s.routeSvc, s.miningSvc, s.bountySvc, s.combatSvc, s.index)
 	s.monitors[charID] = monitor
 
 	s.wg.Add(1) // Add to waitgroup for this monitor
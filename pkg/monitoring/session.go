@@ -0,0 +1,137 @@
+package monitoring
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/character"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// loginWindow is how recently a session must have started for a newly found
+// gamelog to count as a fresh login rather than an old, already running client.
+const loginWindow = 5 * time.Minute
+
+// disconnectRegex matches the notices the client logs when it loses the server.
+var disconnectRegex = regexp.MustCompile(`(?i)(connection (to (the )?server )?(has been |was )?lost|socket (was )?closed|disconnected from (the )?server)`)
+
+// sessionTracker infers whether a character's client is online from its gamelog.
+// It is shared between the monitor loop and the gamelog worker, so it is locked.
+type sessionTracker struct {
+	mu           sync.Mutex
+	online       bool
+	lastActivity time.Time
+	idleNotified bool
+}
+
+// sessionEvent is a lifecycle change worth notifying about.
+type sessionEvent int
+
+const (
+	sessionNone sessionEvent = iota
+	sessionLogin
+	sessionDisconnect
+	sessionIdle
+)
+
+// onNewLog is called when a gamelog starts being watched. It reports a login if
+// the header says the session started just now.
+func (t *sessionTracker) onNewLog(header *character.LogHeader, modTime time.Time, now time.Time) sessionEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// A log that has not been written to recently most likely belongs to a
+	// closed client; wait for a new line before treating it as online.
+	t.online = now.Sub(modTime) < loginWindow
+	t.lastActivity = modTime
+	t.idleNotified = false
+
+	if header == nil || header.SessionStarted.IsZero() {
+		return sessionNone
+	}
+	if now.Sub(header.SessionStarted) > loginWindow {
+		return sessionNone
+	}
+	return sessionLogin
+}
+
+// onLine records activity and reports a disconnect if the line is a
+// connection-lost notice.
+func (t *sessionTracker) onLine(line gamelogLine, now time.Time) sessionEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastActivity = now
+	t.idleNotified = false
+	if !disconnectRegex.MatchString(line.Text) {
+		t.online = true
+		return sessionNone
+	}
+	if !t.online {
+		return sessionNone
+	}
+	t.online = false
+	return sessionDisconnect
+}
+
+// checkIdle reports an idle client once the log has been silent for longer
+// than the threshold. It fires once per silence.
+func (t *sessionTracker) checkIdle(threshold time.Duration, now time.Time) sessionEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.online || t.idleNotified || t.lastActivity.IsZero() || threshold <= 0 {
+		return sessionNone
+	}
+	if now.Sub(t.lastActivity) < threshold {
+		return sessionNone
+	}
+	t.idleNotified = true
+	return sessionIdle
+}
+
+// pause marks the client offline without reporting anything, e.g. while the
+// server is down. The next log line brings it back online.
+func (t *sessionTracker) pause() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.online = false
+	t.idleNotified = false
+}
+
+// notifySession sends the notification for a session event, if the character
+// has the matching option enabled.
+func (m *characterMonitor) notifySession(event sessionEvent) {
+	if event == sessionDisconnect || event == sessionIdle {
+		m.finishBounties()
+	}
+	settings, exists := m.subSvc.GetSettings(m.charID)
+	if !exists || event == sessionNone {
+		return
+	}
+
+	title := "EVE Notify - Session"
+	switch event {
+	case sessionLogin:
+		if !settings.SessionEvents {
+			return
+		}
+		logger.Sugar.Infof("[%d] Character logged in.", m.charID)
+		m.notifSvc.Notify(title, m.message("Logged in."), false)
+	case sessionDisconnect:
+		if !settings.SessionEvents {
+			return
+		}
+		logger.Sugar.Infof("[%d] Character disconnected.", m.charID)
+		m.notifSvc.Notify(title, m.message("Client disconnected from the server!"), true)
+	case sessionIdle:
+		if !settings.ClientIdle {
+			return
+		}
+		minutes := m.configSvc.GetClientIdleMinutes()
+		logger.Sugar.Infof("[%d] Client idle for %d minutes.", m.charID, minutes)
+		m.notifSvc.Notify(title, m.message(fmt.Sprintf("No log activity for %d minutes.", minutes)), true)
+	}
+}
@@ -11,15 +11,12 @@ import (
 	"time"
 
 	"github.com/FabricSoul/eve-notify/pkg/logger"
-	"github.com/FabricSoul/eve-notify/pkg/subscription"
 )
 
-var ( miningFullRegex = regexp.MustCompile(`Ship's cargo hold is full`)
-	manualAutopilotRegex = regexp.MustCompile(`Jumping from`)
-)
+var miningFullRegex = regexp.MustCompile(`Ship's cargo hold is full`)
 
 // miningWorker tails a gamelog file and looks for "cargo full" messages.
-func (m *characterMonitor) gamelogWorker(ctx context.Context, filePath string, settings *subscription.NotificationSettings) {
+func (m *characterMonitor) gamelogWorker(ctx context.Context, filePath string) {
 	logger.Sugar.Infof("[%d] Mining worker started for file: %s", m.charID, filePath)
 
 	file, err := os.Open(filePath)
@@ -52,18 +49,74 @@ func (m *characterMonitor) gamelogWorker(ctx context.Context, filePath string, s
 			}
 
 			line = strings.TrimSpace(line)
+
+			// Settings are looked up per line so profile edits apply without a restart.
+			settings, exists := m.subSvc.GetSettings(m.charID)
+			if !exists {
+				return
+			}
+			if parsed, ok := parseGamelogLine(line); ok {
+				event := m.session.onLine(parsed, time.Now())
+				if event == sessionDisconnect && m.statusSvc.DowntimePaused(time.Now()) {
+					logger.Sugar.Infof("[%d] Disconnected during downtime; not notifying.", m.charID)
+					m.session.pause()
+					event = sessionNone
+				}
+				m.notifySession(event)
+
+				if from, to, ok := parseJump(parsed.Text); ok {
+					if loc, changed := m.locationSvc.Set(m.charID, to, parsed.Time); changed {
+						m.onLocationChanged(loc)
+					}
+					message := fmt.Sprintf("Jumping from %s to %s.", from, to)
+					if progress := m.onJump(to, parsed.Time, settings.AutopilotRoute); progress != "" {
+						message += " " + progress
+					}
+					if settings.ManualAutopilot {
+						m.notifSvc.Notify("EVE Notify - Autopilot", m.message(message), true)
+					}
+				} else {
+					m.onRouteNotice(parsed.Text, settings.AutopilotRoute)
+				}
+
+				if parsed.Channel == "mining" {
+					if cycle, ok := parseMining(parsed); ok {
+						m.miningSvc.Record(m.charID, m.sessionStarted(), cycle)
+						if !cycle.Critical {
+							m.miningIdle.onCycle(cycle.Time)
+						}
+						m.predictHoldFull(cycle, settings.CargoFullSoon)
+					}
+				}
+				m.onMiningNotice(parsed, settings.MiningIdle)
+
+				if parsed.Channel == "bounty" {
+					if b, ok := parseBounty(parsed); ok {
+						m.bountySvc.Record(m.charID, m.sessionStarted(), b)
+					}
+				}
+
+				if parsed.Channel == "combat" {
+					if hit, ok := parseCombat(parsed); ok {
+						m.combatSvc.Record(m.charID, m.sessionStarted(), hit)
+						if hit.Incoming && !hit.Miss {
+							m.checkIncomingDPS(hit, settings.IncomingDPS)
+						}
+					}
+				}
+			}
+
+			if miningFullRegex.MatchString(line) {
+				m.miningSvc.HoldFull(m.charID)
+				m.miningIdle.stop()
+			}
 			if settings.MiningStorageFull && miningFullRegex.MatchString(line) {
 				logger.Sugar.Infof("!!! MINING NOTIFICATION FOR CHAR %d: Cargo is full!", m.charID)
 
 				title := "EVE Notify - Mining"
-				message := fmt.Sprintf("Character %d: Your ship's cargo hold is full.", m.charID)
+				message := m.message("Your ship's cargo hold is full.")
 				m.notifSvc.Notify(title, message, true)
 			}
-		if settings.ManualAutopilot && manualAutopilotRegex.MatchString(line) {
-				title := "EVE Notify - Autopilot"
-				message := fmt.Sprintf("Character %d: Manually jumping.", m.charID)
-				m.notifSvc.Notify(title, message, true) // Autopilot jumps are frequent, maybe no sound
-			}
 		}
 	}
 }
@@ -53,6 +53,17 @@ func (s *Service) Notify(title, message string, withSound bool) {
 	})
 }
 
+// NotifyRepeat sends a notification and plays the sound repeats times, so
+// alerts can be told apart by ear.
+func (s *Service) NotifyRepeat(title, message string, repeats int) {
+	s.Notify(title, message, false)
+	go func() {
+		for i := 0; i < repeats; i++ {
+			s.PlaySound()
+		}
+	}()
+}
+
 // PlaySound is unchanged and still correct.
 func (s *Service) PlaySound() {
 	if s.otoCtx == nil {
@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// DefaultTemplate is used for character alerts when the user has not set one.
+const DefaultTemplate = "{{.Character}}: {{.Message}}"
+
+// MessageData is what a message template can refer to.
+type MessageData struct {
+	Character string // Character name.
+	System    string // Current solar system, or "" if unknown.
+	Message   string // The alert itself, e.g. "Your ship's cargo hold is full."
+}
+
+// ParseTemplate checks a message template. An empty text is the default template.
+func ParseTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultTemplate
+	}
+	return template.New("message").Option("missingkey=zero").Parse(text)
+}
+
+// FormatMessage renders a character alert with a user template, falling back
+// to the default template if the user's one is broken.
+func FormatMessage(text string, data MessageData) string {
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		logger.Sugar.Warnf("Invalid message template %q, using the default: %v", text, err)
+		tmpl, _ = ParseTemplate("")
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		logger.Sugar.Warnf("Failed to render message template %q: %v", text, err)
+		return data.Character + ": " + data.Message
+	}
+	return sb.String()
+}
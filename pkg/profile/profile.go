@@ -0,0 +1,296 @@
+package profile
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/subscription"
+)
+
+// Keys for storing preferences. Using constants prevents typos.
+const (
+	keyProfiles    = "profiles"
+	keyAssignments = "profile_assignments"
+	keyGroups      = "character_groups"
+)
+
+// Service manages named settings profiles and character groups.
+// Profiles, profile assignments and groups are persisted in the app preferences.
+type Service struct {
+	prefs  fyne.Preferences
+	subSvc *subscription.Service
+	mu     sync.RWMutex
+
+	// profiles maps a profile name to its notification settings.
+	profiles map[string]subscription.NotificationSettings
+	// assignments maps a character ID to the name of the profile it uses.
+	assignments map[int64]string
+	// groups maps a character ID to the group tags it has been given.
+	groups map[int64][]string
+
+	// listeners are called whenever the set of profiles changes.
+	listeners []func()
+}
+
+// NewService creates a new profile service and loads any saved state.
+func NewService(app fyne.App, subSvc *subscription.Service) *Service {
+	s := &Service{
+		prefs:       app.Preferences(),
+		subSvc:      subSvc,
+		profiles:    make(map[string]subscription.NotificationSettings),
+		assignments: make(map[int64]string),
+		groups:      make(map[int64][]string),
+	}
+	s.load(keyProfiles, &s.profiles)
+	s.load(keyAssignments, &s.assignments)
+	s.load(keyGroups, &s.groups)
+	logger.Sugar.Infof("Loaded %d settings profiles.", len(s.profiles))
+	return s
+}
+
+// Profiles returns the names of all profiles, sorted alphabetically.
+func (s *Service) Profiles() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.profiles))
+	for name := range s.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetProfile returns a copy of the settings stored under a profile name.
+func (s *Service) GetProfile(name string) (*subscription.NotificationSettings, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	settings, exists := s.profiles[name]
+	if !exists {
+		return nil, false
+	}
+	return &settings, true
+}
+
+// SaveProfile creates or updates a profile. Subscribed characters using the
+// profile receive the new settings immediately.
+func (s *Service) SaveProfile(name string, settings *subscription.NotificationSettings) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return
+	}
+
+	s.mu.Lock()
+	s.profiles[name] = *settings
+	s.save(keyProfiles, s.profiles)
+	members := s.usersOfLocked(name)
+	s.mu.Unlock()
+
+	logger.Sugar.Infof("Saved profile '%s', propagating to %d characters.", name, len(members))
+	for _, charID := range members {
+		settingsCopy := *settings
+		s.subSvc.UpdateSettings(charID, &settingsCopy)
+	}
+	s.notifyListeners()
+}
+
+// DeleteProfile removes a profile and detaches every character that used it.
+// Subscribed characters keep their current settings.
+func (s *Service) DeleteProfile(name string) {
+	s.mu.Lock()
+	delete(s.profiles, name)
+	for _, charID := range s.usersOfLocked(name) {
+		delete(s.assignments, charID)
+	}
+	s.save(keyProfiles, s.profiles)
+	s.save(keyAssignments, s.assignments)
+	s.mu.Unlock()
+
+	logger.Sugar.Infof("Deleted profile '%s'.", name)
+	s.notifyListeners()
+}
+
+// OnProfilesChanged registers a function that is called after a profile is
+// saved or deleted.
+func (s *Service) OnProfilesChanged(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *Service) notifyListeners() {
+	s.mu.RLock()
+	listeners := append([]func(){}, s.listeners...)
+	s.mu.RUnlock()
+	for _, fn := range listeners {
+		fn()
+	}
+}
+
+// ProfileFor returns the profile name assigned to a character, or "" if none.
+func (s *Service) ProfileFor(charID int64) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.assignments[charID]
+}
+
+// AssignProfile makes a character use a profile. An empty name removes the
+// assignment. If the character is subscribed, its settings are updated.
+func (s *Service) AssignProfile(charID int64, name string) {
+	s.mu.Lock()
+	if name == "" {
+		delete(s.assignments, charID)
+		s.save(keyAssignments, s.assignments)
+		s.mu.Unlock()
+		return
+	}
+	settings, exists := s.profiles[name]
+	if !exists {
+		s.mu.Unlock()
+		logger.Sugar.Warnf("Cannot assign unknown profile '%s' to character %d.", name, charID)
+		return
+	}
+	s.assignments[charID] = name
+	s.save(keyAssignments, s.assignments)
+	s.mu.Unlock()
+
+	s.subSvc.UpdateSettings(charID, &settings)
+}
+
+// SettingsFor returns the settings a character should be subscribed with:
+// its profile's settings if it has one, otherwise empty settings.
+func (s *Service) SettingsFor(charID int64) *subscription.NotificationSettings {
+	if settings, ok := s.GetProfile(s.ProfileFor(charID)); ok {
+		return settings
+	}
+	return &subscription.NotificationSettings{}
+}
+
+// Groups returns the names of all groups that have at least one member, sorted.
+func (s *Service) Groups() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	seen := make(map[string]bool)
+	var names []string
+	for _, tags := range s.groups {
+		for _, tag := range tags {
+			if !seen[tag] {
+				seen[tag] = true
+				names = append(names, tag)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GroupsFor returns the group tags of a character.
+func (s *Service) GroupsFor(charID int64) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.groups[charID]...)
+}
+
+// SetGroups replaces the group tags of a character. Tags are trimmed and
+// de-duplicated; empty tags are dropped.
+func (s *Service) SetGroups(charID int64, tags []string) {
+	seen := make(map[string]bool)
+	var clean []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		clean = append(clean, tag)
+	}
+	sort.Strings(clean)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(clean) == 0 {
+		delete(s.groups, charID)
+	} else {
+		s.groups[charID] = clean
+	}
+	s.save(keyGroups, s.groups)
+}
+
+// Members returns the IDs of all characters tagged with a group.
+func (s *Service) Members(group string) []int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var members []int64
+	for charID, tags := range s.groups {
+		for _, tag := range tags {
+			if tag == group {
+				members = append(members, charID)
+				break
+			}
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i] < members[j] })
+	return members
+}
+
+// ApplyProfileToGroup assigns a profile to every member of a group.
+func (s *Service) ApplyProfileToGroup(group, name string) {
+	members := s.Members(group)
+	logger.Sugar.Infof("Applying profile '%s' to %d characters in group '%s'.", name, len(members), group)
+	for _, charID := range members {
+		s.AssignProfile(charID, name)
+	}
+}
+
+// SubscribeGroup subscribes every member of a group that is not already
+// subscribed, using the settings of their assigned profile.
+func (s *Service) SubscribeGroup(group string) {
+	for _, charID := range s.Members(group) {
+		if !s.subSvc.IsSubscribed(charID) {
+			s.subSvc.Subscribe(charID, s.SettingsFor(charID))
+		}
+	}
+}
+
+// UnsubscribeGroup unsubscribes every member of a group.
+func (s *Service) UnsubscribeGroup(group string) {
+	for _, charID := range s.Members(group) {
+		s.subSvc.Unsubscribe(charID)
+	}
+}
+
+// usersOfLocked returns the characters assigned to a profile.
+// The caller must hold the lock.
+func (s *Service) usersOfLocked(name string) []int64 {
+	var users []int64
+	for charID, assigned := range s.assignments {
+		if assigned == name {
+			users = append(users, charID)
+		}
+	}
+	return users
+}
+
+// load decodes a JSON value stored under a preference key.
+func (s *Service) load(key string, v interface{}) {
+	raw := s.prefs.String(key)
+	if raw == "" {
+		return
+	}
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		logger.Sugar.Errorf("Failed to decode preference '%s': %v", key, err)
+	}
+}
+
+// save encodes a value as JSON and stores it under a preference key.
+func (s *Service) save(key string, v interface{}) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		logger.Sugar.Errorf("Failed to encode preference '%s': %v", key, err)
+		return
+	}
+	s.prefs.SetString(key, string(raw))
+}
@@ -0,0 +1,225 @@
+package route
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/sde"
+)
+
+const (
+	// Jumps further apart than this are not one trip, so they don't count
+	// towards the time per jump.
+	maxJumpInterval = 5 * time.Minute
+	// recentJumps is how many jump intervals are averaged.
+	recentJumps = 10
+	// A character is stuck once it hasn't jumped for stuckFactor times its
+	// usual time per jump, and never sooner than minStuckAfter.
+	stuckFactor   = 3
+	minStuckAfter = 2 * time.Minute
+	// defaultStuckAfter applies until a time per jump has been observed.
+	defaultStuckAfter = 3 * time.Minute
+)
+
+// ErrNoUniverse is returned when routes can't be planned without universe data.
+var ErrNoUniverse = errors.New("no universe data. " + sde.ImportHint)
+
+// Progress is how far a character is along its route.
+type Progress struct {
+	Destination string
+	Jumps       int           // Jumps left from the current system, -1 if there is no route.
+	PerJump     time.Duration // Average time per jump, 0 until observed.
+	Remaining   time.Duration // Estimated time to arrival, 0 if PerJump is unknown.
+	LastJump    time.Time     // Zero until the first jump towards the destination.
+	JumpsMade   int           // Jumps made since the destination was set.
+}
+
+// Summary describes the jumps left and the time they should take.
+func (p Progress) Summary() string {
+	if p.Jumps < 0 {
+		return fmt.Sprintf("No known route to %s.", p.Destination)
+	}
+	jumps := fmt.Sprintf("%d jumps", p.Jumps)
+	if p.Jumps == 1 {
+		jumps = "1 jump"
+	}
+	if p.Remaining > 0 {
+		return fmt.Sprintf("%s to %s, about %s.", jumps, p.Destination, p.Remaining.Round(time.Minute))
+	}
+	return fmt.Sprintf("%s to %s.", jumps, p.Destination)
+}
+
+type route struct {
+	destination   string
+	lastJump      time.Time
+	jumps         int
+	stuckNotified bool
+}
+
+// Service keeps each character's autopilot destination and learns how long
+// its jumps take, to estimate arrival and notice when it stops moving.
+type Service struct {
+	universe *sde.Universe
+	mu       sync.Mutex
+	routes   map[int64]*route
+	lastJump map[int64]time.Time
+	perJump  map[int64][]time.Duration
+}
+
+// NewService creates a route tracker using the given universe data.
+func NewService(universe *sde.Universe) *Service {
+	return &Service{
+		universe: universe,
+		routes:   make(map[int64]*route),
+		lastJump: make(map[int64]time.Time),
+		perJump:  make(map[int64][]time.Duration),
+	}
+}
+
+// SetDestination sets the system a character is travelling to. An empty name
+// clears the route.
+func (s *Service) SetDestination(charID int64, system string) error {
+	if system == "" {
+		s.ClearDestination(charID)
+		return nil
+	}
+	if s.universe.Empty() {
+		return ErrNoUniverse
+	}
+	destination, ok := s.universe.SystemByName(system)
+	if !ok {
+		return fmt.Errorf("unknown solar system %q", system)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[charID] = &route{destination: destination.Name}
+	logger.Sugar.Infof("[%d] Destination set to %s.", charID, destination.Name)
+	return nil
+}
+
+// ClearDestination forgets a character's route.
+func (s *Service) ClearDestination(charID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.routes[charID]; ok {
+		delete(s.routes, charID)
+		logger.Sugar.Infof("[%d] Destination cleared.", charID)
+	}
+}
+
+// Destination returns the system a character is travelling to, or "".
+func (s *Service) Destination(charID int64) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.routes[charID]; ok {
+		return r.destination
+	}
+	return ""
+}
+
+// Progress returns how far the character in current is from its destination.
+func (s *Service) Progress(charID int64, current string) (Progress, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.routes[charID]
+	if !ok {
+		return Progress{}, false
+	}
+	return s.progressLocked(charID, r, current), true
+}
+
+// Jumped records a jump into a system. It returns the progress afterwards, or
+// false if the character has no route. A jump into the destination leaves no
+// jumps and ends the route.
+func (s *Service) Jumped(charID int64, to string, at time.Time) (Progress, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if previous, ok := s.lastJump[charID]; ok {
+		if interval := at.Sub(previous); interval > 0 && interval <= maxJumpInterval {
+			times := append(s.perJump[charID], interval)
+			if len(times) > recentJumps {
+				times = times[len(times)-recentJumps:]
+			}
+			s.perJump[charID] = times
+		}
+	}
+	s.lastJump[charID] = at
+
+	r, ok := s.routes[charID]
+	if !ok {
+		return Progress{}, false
+	}
+	r.lastJump = at
+	r.jumps++
+	r.stuckNotified = false
+	progress := s.progressLocked(charID, r, to)
+	if progress.Jumps == 0 {
+		delete(s.routes, charID)
+	}
+	return progress, true
+}
+
+// Arrived ends a character's route, returning its progress so far. It is used
+// when the client reports the destination reached.
+func (s *Service) Arrived(charID int64, current string) (Progress, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.routes[charID]
+	if !ok {
+		return Progress{}, false
+	}
+	delete(s.routes, charID)
+	return s.progressLocked(charID, r, current), true
+}
+
+// CheckStuck reports once per stop that a character on a route has not jumped
+// for longer than expected. Routes with no jump yet are not checked, as the
+// character may not have undocked.
+func (s *Service) CheckStuck(charID int64, current string, now time.Time) (Progress, time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.routes[charID]
+	if !ok || r.lastJump.IsZero() || r.stuckNotified {
+		return Progress{}, 0, false
+	}
+	progress := s.progressLocked(charID, r, current)
+	stuckAfter := defaultStuckAfter
+	if progress.PerJump > 0 {
+		stuckAfter = max(stuckFactor*progress.PerJump, minStuckAfter)
+	}
+	since := now.Sub(r.lastJump)
+	if since < stuckAfter {
+		return Progress{}, 0, false
+	}
+	r.stuckNotified = true
+	return progress, since, true
+}
+
+func (s *Service) progressLocked(charID int64, r *route, current string) Progress {
+	progress := Progress{Destination: r.destination, Jumps: -1, LastJump: r.lastJump, JumpsMade: r.jumps}
+	if times := s.perJump[charID]; len(times) > 0 {
+		var total time.Duration
+		for _, t := range times {
+			total += t
+		}
+		progress.PerJump = total / time.Duration(len(times))
+	}
+	from, ok := s.universe.SystemByName(current)
+	if !ok {
+		return progress
+	}
+	to, ok := s.universe.SystemByName(r.destination)
+	if !ok {
+		return progress
+	}
+	progress.Jumps = s.universe.Jumps(from.ID, to.ID)
+	if progress.Jumps > 0 && progress.PerJump > 0 {
+		progress.Remaining = time.Duration(progress.Jumps) * progress.PerJump
+	}
+	return progress
+}
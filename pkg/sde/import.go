@@ -0,0 +1,258 @@
+package sde
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Files of the official SDE zip (sde.zip) read by Import. Paths inside the zip
+// may carry a leading "sde/".
+const (
+	universePrefix    = "fsd/universe/"
+	namesFile         = "bsd/invNames.yaml"
+	regionFile        = "region.staticdata"
+	constellationFile = "constellation.staticdata"
+	systemFile        = "solarsystem.staticdata"
+	typesFile         = "fsd/typeIDs.yaml"
+	groupsFile        = "fsd/groupIDs.yaml"
+)
+
+// asteroidCategory is the inventory category of everything that is mined:
+// asteroid and moon ore, ice and gas clouds.
+const asteroidCategory = 25
+
+// Only these parts of the universe are imported; the rest (abyssal space,
+// test regions) cannot be visited normally.
+var importedSpaces = []string{"eve", "wormhole"}
+
+type regionData struct {
+	RegionID int64 `yaml:"regionID"`
+}
+
+type constellationData struct {
+	ConstellationID int64 `yaml:"constellationID"`
+}
+
+type systemData struct {
+	SolarSystemID int64   `yaml:"solarSystemID"`
+	Security      float64 `yaml:"security"`
+	Stargates     map[int64]struct {
+		Destination int64 `yaml:"destination"`
+	} `yaml:"stargates"`
+}
+
+type typeData struct {
+	GroupID   int64             `yaml:"groupID"`
+	Name      map[string]string `yaml:"name"`
+	Volume    float64           `yaml:"volume"`
+	Published bool              `yaml:"published"`
+}
+
+type groupData struct {
+	CategoryID int64 `yaml:"categoryID"`
+}
+
+type nameData struct {
+	ItemID   int64  `yaml:"itemID"`
+	ItemName string `yaml:"itemName"`
+}
+
+// Import builds a dataset from the official SDE zip. Regions, constellations
+// and systems are found by their place in the directory tree and named from
+// invNames; stargates are reduced to the systems they lead to. Published types
+// in the asteroid category become ores.
+func Import(zipPath string) (Dataset, error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return Dataset{}, fmt.Errorf("failed to open SDE: %w", err)
+	}
+	defer archive.Close()
+	return importArchive(&archive.Reader, zipPath)
+}
+
+// importArchive builds a dataset from an opened SDE zip; source names it in
+// errors.
+func importArchive(archive *zip.Reader, source string) (Dataset, error) {
+	var data Dataset
+	var err error
+	dirIDs := make(map[string]int64) // directory -> region or constellation ID
+	gateSystem := make(map[int64]int64)
+	gateDestinations := make(map[int64][]int64)
+	var names map[int64]string
+	var types map[int64]typeData
+	var groups map[int64]groupData
+
+	// Region and constellation files must be read before the systems below
+	// them, so sort by depth.
+	files := append([]*zip.File(nil), archive.File...)
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.Count(files[i].Name, "/") < strings.Count(files[j].Name, "/")
+	})
+
+	for _, f := range files {
+		name := strings.TrimPrefix(f.Name, "sde/")
+		if name == namesFile {
+			if names, err = readNames(f); err != nil {
+				return Dataset{}, err
+			}
+			continue
+		}
+		switch name {
+		case typesFile:
+			if err := readYAML(f, &types); err != nil {
+				return Dataset{}, err
+			}
+			continue
+		case groupsFile:
+			if err := readYAML(f, &groups); err != nil {
+				return Dataset{}, err
+			}
+			continue
+		}
+		rest, ok := strings.CutPrefix(name, universePrefix)
+		if !ok || !importedSpace(rest) {
+			continue
+		}
+		dir := path.Dir(name)
+
+		switch path.Base(name) {
+		case regionFile:
+			var region regionData
+			if err := readYAML(f, &region); err != nil {
+				return Dataset{}, err
+			}
+			dirIDs[dir] = region.RegionID
+			data.Regions = append(data.Regions, Region{ID: region.RegionID, Name: path.Base(dir)})
+		case constellationFile:
+			var constellation constellationData
+			if err := readYAML(f, &constellation); err != nil {
+				return Dataset{}, err
+			}
+			dirIDs[dir] = constellation.ConstellationID
+			data.Constellations = append(data.Constellations, Constellation{
+				ID:       constellation.ConstellationID,
+				Name:     path.Base(dir),
+				RegionID: dirIDs[path.Dir(dir)],
+			})
+		case systemFile:
+			var system systemData
+			if err := readYAML(f, &system); err != nil {
+				return Dataset{}, err
+			}
+			constellationDir := path.Dir(dir)
+			for gateID, gate := range system.Stargates {
+				gateSystem[gateID] = system.SolarSystemID
+				gateDestinations[system.SolarSystemID] = append(gateDestinations[system.SolarSystemID], gate.Destination)
+			}
+			data.Systems = append(data.Systems, System{
+				ID:              system.SolarSystemID,
+				Name:            path.Base(dir),
+				ConstellationID: dirIDs[constellationDir],
+				RegionID:        dirIDs[path.Dir(constellationDir)],
+				Security:        system.Security,
+			})
+		}
+	}
+	if len(data.Systems) == 0 {
+		return Dataset{}, fmt.Errorf("no solar systems found in %s; is it the SDE zip?", source)
+	}
+
+	// Directory names have their spaces removed, so prefer the real names.
+	for i := range data.Regions {
+		data.Regions[i].Name = nameOr(names, data.Regions[i].ID, data.Regions[i].Name)
+	}
+	for i := range data.Constellations {
+		data.Constellations[i].Name = nameOr(names, data.Constellations[i].ID, data.Constellations[i].Name)
+	}
+	for i := range data.Systems {
+		system := &data.Systems[i]
+		system.Name = nameOr(names, system.ID, system.Name)
+		for _, gateID := range gateDestinations[system.ID] {
+			if neighbour, ok := gateSystem[gateID]; ok {
+				system.Neighbours = append(system.Neighbours, neighbour)
+			}
+		}
+		sort.Slice(system.Neighbours, func(a, b int) bool { return system.Neighbours[a] < system.Neighbours[b] })
+	}
+	sort.Slice(data.Systems, func(i, j int) bool { return data.Systems[i].ID < data.Systems[j].ID })
+
+	for id, t := range types {
+		if !t.Published || groups[t.GroupID].CategoryID != asteroidCategory || t.Name["en"] == "" {
+			continue
+		}
+		data.Ores = append(data.Ores, Ore{ID: id, Name: t.Name["en"], Volume: t.Volume})
+	}
+	sort.Slice(data.Ores, func(i, j int) bool { return data.Ores[i].ID < data.Ores[j].ID })
+	return data, nil
+}
+
+// ImportFile imports the SDE zip and writes the dataset to outPath.
+func ImportFile(zipPath, outPath string) (Dataset, error) {
+	data, err := Import(zipPath)
+	if err != nil {
+		return Dataset{}, err
+	}
+	tmpPath := outPath + ".tmp"
+	out, err := os.Create(tmpPath)
+	if err != nil {
+		return Dataset{}, fmt.Errorf("failed to create %s: %w", tmpPath, err)
+	}
+	if err := Encode(out, data); err != nil {
+		out.Close()
+		return Dataset{}, err
+	}
+	if err := out.Close(); err != nil {
+		return Dataset{}, fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, outPath); err != nil {
+		return Dataset{}, fmt.Errorf("failed to replace %s: %w", outPath, err)
+	}
+	return data, nil
+}
+
+func importedSpace(rest string) bool {
+	for _, space := range importedSpaces {
+		if strings.HasPrefix(rest, space+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func readYAML(f *zip.File, out interface{}) error {
+	r, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer r.Close()
+	if err := yaml.NewDecoder(r).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to parse %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+func readNames(f *zip.File) (map[int64]string, error) {
+	var entries []nameData
+	if err := readYAML(f, &entries); err != nil {
+		return nil, err
+	}
+	names := make(map[int64]string, len(entries))
+	for _, entry := range entries {
+		names[entry.ItemID] = entry.ItemName
+	}
+	return names, nil
+}
+
+func nameOr(names map[int64]string, id int64, fallback string) string {
+	if name, ok := names[id]; ok && name != "" {
+		return name
+	}
+	return fallback
+}
@@ -0,0 +1,266 @@
+package sde
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+const (
+	// FileName is the name of an imported dataset in the data directory.
+	FileName = "universe.json.gz"
+	// ImportHint tells the user how to install the dataset, which is not
+	// shipped with the binary.
+	ImportHint = `Run "eve-notify import-sde <sde.zip>" with the SDE from developers.eveonline.com, then restart.`
+)
+
+// Region is a region of New Eden.
+type Region struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Constellation is a constellation and the region it belongs to.
+type Constellation struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	RegionID int64  `json:"region"`
+}
+
+// System is a solar system with the systems its stargates lead to.
+type System struct {
+	ID              int64   `json:"id"`
+	Name            string  `json:"name"`
+	ConstellationID int64   `json:"constellation"`
+	RegionID        int64   `json:"region"`
+	Security        float64 `json:"security"`
+	Neighbours      []int64 `json:"gates,omitempty"`
+}
+
+// Ore is a type that can be mined: asteroid ore, moon ore, ice or gas.
+type Ore struct {
+	ID     int64   `json:"id"`
+	Name   string  `json:"name"`
+	Volume float64 `json:"volume"` // m³ per unit.
+}
+
+// Dataset is the serialized form of the static universe data.
+type Dataset struct {
+	Regions        []Region        `json:"regions"`
+	Constellations []Constellation `json:"constellations"`
+	Systems        []System        `json:"systems"`
+	Ores           []Ore           `json:"ores,omitempty"`
+}
+
+// Universe answers lookups on a loaded dataset. It is read-only and safe for
+// concurrent use.
+type Universe struct {
+	regions        map[int64]*Region
+	constellations map[int64]*Constellation
+	systems        map[int64]*System
+	systemsByName  map[string]*System
+	oresByName     map[string]*Ore
+}
+
+// Load reads the dataset imported into dataDir. Without one the universe is
+// empty and the features that need it stay off.
+func Load(dataDir string) *Universe {
+	path := filepath.Join(dataDir, FileName)
+	raw, err := os.ReadFile(path)
+	if err == nil {
+		universe, err := Decode(raw)
+		if err == nil {
+			logger.Sugar.Infof("Loaded %d systems from %s", len(universe.systems), path)
+			return universe
+		}
+		logger.Sugar.Warnf("Ignoring unreadable universe data %s: %v", path, err)
+	} else if !os.IsNotExist(err) {
+		logger.Sugar.Warnf("Could not read universe data %s: %v", path, err)
+	}
+
+	logger.Sugar.Warnf("No universe data available. %s", ImportHint)
+	return New(Dataset{})
+}
+
+// Decode reads a gzipped JSON dataset.
+func Decode(raw []byte) (*Universe, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open universe data: %w", err)
+	}
+	defer zr.Close()
+	var data Dataset
+	if err := json.NewDecoder(zr).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode universe data: %w", err)
+	}
+	return New(data), nil
+}
+
+// Encode writes a dataset as gzipped JSON.
+func Encode(w io.Writer, data Dataset) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(data); err != nil {
+		return fmt.Errorf("failed to encode universe data: %w", err)
+	}
+	return zw.Close()
+}
+
+// New indexes a dataset for lookups.
+func New(data Dataset) *Universe {
+	u := &Universe{
+		regions:        make(map[int64]*Region, len(data.Regions)),
+		constellations: make(map[int64]*Constellation, len(data.Constellations)),
+		systems:        make(map[int64]*System, len(data.Systems)),
+		systemsByName:  make(map[string]*System, len(data.Systems)),
+		oresByName:     make(map[string]*Ore, len(data.Ores)),
+	}
+	for i := range data.Regions {
+		u.regions[data.Regions[i].ID] = &data.Regions[i]
+	}
+	for i := range data.Constellations {
+		u.constellations[data.Constellations[i].ID] = &data.Constellations[i]
+	}
+	for i := range data.Systems {
+		system := &data.Systems[i]
+		u.systems[system.ID] = system
+		u.systemsByName[strings.ToLower(system.Name)] = system
+	}
+	for i := range data.Ores {
+		u.oresByName[strings.ToLower(data.Ores[i].Name)] = &data.Ores[i]
+	}
+	return u
+}
+
+// Empty reports whether no systems are known.
+func (u *Universe) Empty() bool {
+	return len(u.systems) == 0
+}
+
+// Summary describes the loaded dataset for the settings window.
+func (u *Universe) Summary() string {
+	if u.Empty() {
+		return "Not installed. " + ImportHint
+	}
+	return fmt.Sprintf("%d systems, %d ores", len(u.systems), len(u.oresByName))
+}
+
+// System looks up a solar system by ID.
+func (u *Universe) System(id int64) (*System, bool) {
+	system, ok := u.systems[id]
+	return system, ok
+}
+
+// SystemByName looks up a solar system by name, ignoring case.
+func (u *Universe) SystemByName(name string) (*System, bool) {
+	system, ok := u.systemsByName[strings.ToLower(strings.TrimSpace(name))]
+	return system, ok
+}
+
+// OreByName looks up a mineable type by name, ignoring case.
+func (u *Universe) OreByName(name string) (*Ore, bool) {
+	ore, ok := u.oresByName[strings.ToLower(strings.TrimSpace(name))]
+	return ore, ok
+}
+
+// Constellation looks up a constellation by ID.
+func (u *Universe) Constellation(id int64) (*Constellation, bool) {
+	constellation, ok := u.constellations[id]
+	return constellation, ok
+}
+
+// Region looks up a region by ID.
+func (u *Universe) Region(id int64) (*Region, bool) {
+	region, ok := u.regions[id]
+	return region, ok
+}
+
+// Jumps returns the number of stargate jumps on the shortest route between two
+// systems, or -1 if there is no route.
+func (u *Universe) Jumps(from, to int64) int {
+	if _, ok := u.systems[from]; !ok {
+		return -1
+	}
+	if from == to {
+		return 0
+	}
+	distances := u.bfs(from, -1, to)
+	if jumps, ok := distances[to]; ok {
+		return jumps
+	}
+	return -1
+}
+
+// WithinJumps returns every system at most maxJumps jumps from a system,
+// mapped to its distance.
+func (u *Universe) WithinJumps(from int64, maxJumps int) map[int64]int {
+	if _, ok := u.systems[from]; !ok {
+		return nil
+	}
+	return u.bfs(from, maxJumps, 0)
+}
+
+// bfs walks the stargate graph breadth first from a system. It stops at
+// maxJumps (if not negative) or once target (if not 0) is reached.
+func (u *Universe) bfs(from int64, maxJumps int, target int64) map[int64]int {
+	distances := map[int64]int{from: 0}
+	queue := []int64{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		distance := distances[current]
+		if maxJumps >= 0 && distance >= maxJumps {
+			continue
+		}
+		for _, next := range u.systems[current].Neighbours {
+			if _, seen := distances[next]; seen {
+				continue
+			}
+			distances[next] = distance + 1
+			if next == target {
+				return distances
+			}
+			if _, ok := u.systems[next]; ok {
+				queue = append(queue, next)
+			}
+		}
+	}
+	return distances
+}
+
+// DisplaySecurity is the security status as the client shows it: rounded to
+// one decimal, except that any positive status shows as at least 0.1.
+func (s *System) DisplaySecurity() float64 {
+	if s.Security > 0 && s.Security < 0.05 {
+		return 0.1
+	}
+	return math.Round(s.Security*10) / 10
+}
+
+// SecurityClass is the band a system's security status falls into.
+type SecurityClass string
+
+const (
+	HighSec SecurityClass = "high-sec"
+	LowSec  SecurityClass = "low-sec"
+	NullSec SecurityClass = "null-sec"
+)
+
+// Class returns whether the system is high, low or null security space.
+func (s *System) Class() SecurityClass {
+	switch security := s.DisplaySecurity(); {
+	case security >= 0.5:
+		return HighSec
+	case security > 0:
+		return LowSec
+	default:
+		return NullSec
+	}
+}
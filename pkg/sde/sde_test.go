@@ -0,0 +1,267 @@
+package sde
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	sync := logger.Init()
+	code := m.Run()
+	sync()
+	os.Exit(code)
+}
+
+// testUniverse is a small gate graph: 1-2-3 in a line, 4 hanging off 2, 5
+// unreachable, and 6 with a gate to a system missing from the data.
+func testUniverse() *Universe {
+	return New(Dataset{
+		Regions:        []Region{{ID: 10, Name: "Region"}},
+		Constellations: []Constellation{{ID: 20, Name: "Constellation", RegionID: 10}},
+		Systems: []System{
+			{ID: 1, Name: "Alpha", ConstellationID: 20, RegionID: 10, Security: 0.9, Neighbours: []int64{2}},
+			{ID: 2, Name: "Bravo", ConstellationID: 20, RegionID: 10, Security: 0.45, Neighbours: []int64{1, 3, 4}},
+			{ID: 3, Name: "Charlie", ConstellationID: 20, RegionID: 10, Security: 0.04, Neighbours: []int64{2}},
+			{ID: 4, Name: "Delta", ConstellationID: 20, RegionID: 10, Security: -0.3, Neighbours: []int64{2}},
+			{ID: 5, Name: "Echo", ConstellationID: 20, RegionID: 10},
+			{ID: 6, Name: "Foxtrot", ConstellationID: 20, RegionID: 10, Neighbours: []int64{99}},
+		},
+		Ores: []Ore{{ID: 1230, Name: "Veldspar", Volume: 0.1}},
+	})
+}
+
+func TestJumps(t *testing.T) {
+	u := testUniverse()
+	tests := []struct {
+		name     string
+		from, to int64
+		want     int
+	}{
+		{"same system", 1, 1, 0},
+		{"neighbour", 1, 2, 1},
+		{"along the line", 1, 3, 2},
+		{"through a hub", 3, 4, 2},
+		{"unreachable", 1, 5, -1},
+		{"unknown origin", 42, 1, -1},
+		{"unknown destination", 1, 42, -1},
+		{"gate to missing system", 6, 99, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := u.Jumps(tt.from, tt.to); got != tt.want {
+				t.Errorf("Jumps(%d, %d) = %d, want %d", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithinJumps(t *testing.T) {
+	u := testUniverse()
+	tests := []struct {
+		name     string
+		from     int64
+		maxJumps int
+		want     map[int64]int
+	}{
+		{"no jumps", 1, 0, map[int64]int{1: 0}},
+		{"one jump", 1, 1, map[int64]int{1: 0, 2: 1}},
+		{"two jumps", 1, 2, map[int64]int{1: 0, 2: 1, 3: 2, 4: 2}},
+		{"beyond the graph", 1, 10, map[int64]int{1: 0, 2: 1, 3: 2, 4: 2}},
+		{"isolated", 5, 3, map[int64]int{5: 0}},
+		{"unknown system", 42, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := u.WithinJumps(tt.from, tt.maxJumps); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("WithinJumps(%d, %d) = %v, want %v", tt.from, tt.maxJumps, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecurityClass(t *testing.T) {
+	u := testUniverse()
+	tests := []struct {
+		id      int64
+		display float64
+		class   SecurityClass
+	}{
+		{1, 0.9, HighSec},
+		{2, 0.5, HighSec}, // 0.45 rounds up.
+		{3, 0.1, LowSec},  // Any positive status shows as at least 0.1.
+		{4, -0.3, NullSec},
+		{5, 0, NullSec},
+	}
+	for _, tt := range tests {
+		system, _ := u.System(tt.id)
+		if got := system.DisplaySecurity(); got != tt.display {
+			t.Errorf("%s DisplaySecurity() = %v, want %v", system.Name, got, tt.display)
+		}
+		if got := system.Class(); got != tt.class {
+			t.Errorf("%s Class() = %v, want %v", system.Name, got, tt.class)
+		}
+	}
+}
+
+func TestLookupsIgnoreCase(t *testing.T) {
+	u := testUniverse()
+	if system, ok := u.SystemByName(" bravo "); !ok || system.ID != 2 {
+		t.Errorf("SystemByName(bravo) = %v, %t", system, ok)
+	}
+	if ore, ok := u.OreByName("VELDSPAR"); !ok || ore.Volume != 0.1 {
+		t.Errorf("OreByName(VELDSPAR) = %v, %t", ore, ok)
+	}
+}
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, Dataset{Systems: []System{{ID: 1, Name: "Alpha", Neighbours: []int64{2}}, {ID: 2, Name: "Bravo"}}}); err != nil {
+		t.Fatal(err)
+	}
+	u, err := Decode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Jumps(1, 2) != 1 {
+		t.Errorf("decoded universe lost its gates")
+	}
+}
+
+func TestLoadWithoutDataIsEmpty(t *testing.T) {
+	u := Load(t.TempDir())
+	if !u.Empty() || !strings.Contains(u.Summary(), "import-sde") {
+		t.Errorf("Load without data: empty %t, summary %q", u.Empty(), u.Summary())
+	}
+}
+
+// sdeZip builds an SDE zip in memory. Systems come first to check that
+// regions and constellations are still read before them.
+func sdeZip(t *testing.T, files map[string]string, order []string) *zip.Reader {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range order {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestImportArchive(t *testing.T) {
+	files := map[string]string{
+		"sde/fsd/universe/eve/TheForge/Kimotoro/Jita/solarsystem.staticdata": `
+solarSystemID: 30000142
+security: 0.9459
+stargates:
+  50001248: {destination: 50001249}
+`,
+		"sde/fsd/universe/eve/TheForge/Kimotoro/Perimeter/solarsystem.staticdata": `
+solarSystemID: 30000144
+security: 0.9
+stargates:
+  50001249: {destination: 50001248}
+`,
+		"sde/fsd/universe/eve/TheForge/Kimotoro/constellation.staticdata":             "constellationID: 20000020\n",
+		"sde/fsd/universe/eve/TheForge/region.staticdata":                             "regionID: 10000002\n",
+		"sde/fsd/universe/abyssal/Region/Constellation/System/solarsystem.staticdata": "solarSystemID: 32000001\n",
+		"sde/bsd/invNames.yaml": `
+- {itemID: 10000002, itemName: The Forge}
+- {itemID: 30000142, itemName: Jita}
+`,
+		"sde/fsd/typeIDs.yaml": `
+1230: {groupID: 462, name: {en: Veldspar}, volume: 0.1, published: true}
+28367: {groupID: 462, name: {en: Unpublished Ore}, volume: 1, published: true}
+34: {groupID: 18, name: {en: Tritanium}, volume: 0.01, published: true}
+`,
+		"sde/fsd/groupIDs.yaml": `
+462: {categoryID: 25}
+18: {categoryID: 4}
+`,
+	}
+	order := []string{
+		"sde/fsd/universe/eve/TheForge/Kimotoro/Jita/solarsystem.staticdata",
+		"sde/fsd/universe/eve/TheForge/Kimotoro/Perimeter/solarsystem.staticdata",
+		"sde/fsd/universe/abyssal/Region/Constellation/System/solarsystem.staticdata",
+		"sde/fsd/universe/eve/TheForge/Kimotoro/constellation.staticdata",
+		"sde/fsd/universe/eve/TheForge/region.staticdata",
+		"sde/bsd/invNames.yaml",
+		"sde/fsd/typeIDs.yaml",
+		"sde/fsd/groupIDs.yaml",
+	}
+	// Only published types count; mark the second ore unpublished.
+	files["sde/fsd/typeIDs.yaml"] = strings.Replace(files["sde/fsd/typeIDs.yaml"], "volume: 1, published: true", "volume: 1, published: false", 1)
+
+	data, err := importArchive(sdeZip(t, files, order), "test.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantRegions := []Region{{ID: 10000002, Name: "The Forge"}}
+	if !reflect.DeepEqual(data.Regions, wantRegions) {
+		t.Errorf("regions = %+v, want %+v", data.Regions, wantRegions)
+	}
+	// Without an invNames entry the directory name is kept.
+	wantConstellations := []Constellation{{ID: 20000020, Name: "Kimotoro", RegionID: 10000002}}
+	if !reflect.DeepEqual(data.Constellations, wantConstellations) {
+		t.Errorf("constellations = %+v, want %+v", data.Constellations, wantConstellations)
+	}
+	wantSystems := []System{
+		{ID: 30000142, Name: "Jita", ConstellationID: 20000020, RegionID: 10000002, Security: 0.9459, Neighbours: []int64{30000144}},
+		{ID: 30000144, Name: "Perimeter", ConstellationID: 20000020, RegionID: 10000002, Security: 0.9, Neighbours: []int64{30000142}},
+	}
+	if !reflect.DeepEqual(data.Systems, wantSystems) {
+		t.Errorf("systems = %+v, want %+v", data.Systems, wantSystems)
+	}
+	wantOres := []Ore{{ID: 1230, Name: "Veldspar", Volume: 0.1}}
+	if !reflect.DeepEqual(data.Ores, wantOres) {
+		t.Errorf("ores = %+v, want %+v", data.Ores, wantOres)
+	}
+}
+
+func TestImportRejectsArchiveWithoutSystems(t *testing.T) {
+	files := map[string]string{"readme.txt": "not the SDE"}
+	if _, err := importArchive(sdeZip(t, files, []string{"readme.txt"}), "other.zip"); err == nil || !strings.Contains(err.Error(), "other.zip") {
+		t.Errorf("importArchive error = %v, want one naming the archive", err)
+	}
+}
+
+func TestImportFileWritesLoadableDataset(t *testing.T) {
+	dir := t.TempDir()
+	zipPath := filepath.Join(dir, "sde.zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	entry, _ := w.Create("fsd/universe/eve/R/C/S/solarsystem.staticdata")
+	_, _ = entry.Write([]byte("solarSystemID: 30000001\nsecurity: 0.5\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	if _, err := ImportFile(zipPath, filepath.Join(dir, FileName)); err != nil {
+		t.Fatal(err)
+	}
+	if u := Load(dir); u.Empty() {
+		t.Error("imported dataset did not load")
+	}
+}
@@ -0,0 +1,188 @@
+package serverstatus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/esi"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/notification"
+)
+
+const (
+	// pollInterval is how often /status/ is checked. ESI caches it for 30 seconds.
+	pollInterval = time.Minute
+	// downtimeStart and downtimeEnd bound the daily downtime window in UTC
+	// minutes after midnight. Tranquility goes down at 11:00 and is usually
+	// back well before 11:30; the margin covers the shutdown warnings.
+	downtimeStart = 10*60 + 55
+	downtimeEnd   = 11*60 + 30
+)
+
+// Status is the last known state of Tranquility.
+type Status struct {
+	Online        bool
+	Players       int
+	ServerVersion string
+	StartTime     time.Time
+	VIP           bool
+	CheckedAt     time.Time
+}
+
+// statusResponse is the response of GET /status/.
+type statusResponse struct {
+	Players       int       `json:"players"`
+	ServerVersion string    `json:"server_version"`
+	StartTime     time.Time `json:"start_time"`
+	VIP           bool      `json:"vip"`
+}
+
+// Service watches the Tranquility server status and knows when the daily
+// downtime is under way.
+type Service struct {
+	configSvc *config.Service
+	esiClient *esi.Client
+	notifSvc  *notification.Service
+
+	status Status
+	known  bool // Whether status holds the result of a successful check.
+	mu     sync.RWMutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService creates the server status service.
+func NewService(cfg *config.Service, esiClient *esi.Client, notifSvc *notification.Service) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		configSvc: cfg,
+		esiClient: esiClient,
+		notifSvc:  notifSvc,
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// Start runs the status polling loop. It should be run in a goroutine.
+func (s *Service) Start() {
+	logger.Sugar.Infoln("Server status service started.")
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	s.check()
+	for {
+		select {
+		case <-ticker.C:
+			s.check()
+		case <-s.ctx.Done():
+			logger.Sugar.Infoln("Server status service shutting down.")
+			return
+		}
+	}
+}
+
+// Stop ends the polling loop and waits for it to finish.
+func (s *Service) Stop() {
+	logger.Sugar.Infoln("Stopping server status service...")
+	s.cancel()
+	s.wg.Wait()
+	logger.Sugar.Infoln("Server status service stopped.")
+}
+
+// Status returns the last known server status. ok is false until the first
+// check has completed.
+func (s *Service) Status() (status Status, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status, s.known
+}
+
+// DowntimePaused reports whether detectors that rely on a live connection
+// (disconnect, idle) should stay quiet: during the daily downtime window, and
+// whenever the server is known to be down.
+func (s *Service) DowntimePaused(now time.Time) bool {
+	if InDowntimeWindow(now) {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.known && !s.status.Online
+}
+
+// InDowntimeWindow reports whether t falls in the daily downtime window.
+func InDowntimeWindow(t time.Time) bool {
+	utc := t.UTC()
+	minutes := utc.Hour()*60 + utc.Minute()
+	return minutes >= downtimeStart && minutes < downtimeEnd
+}
+
+// check polls /status/ and notifies about state changes.
+func (s *Service) check() {
+	ctx, cancel := context.WithTimeout(s.ctx, 30*time.Second)
+	defer cancel()
+
+	var resp statusResponse
+	_, err := s.esiClient.Get(ctx, "/status/", &resp)
+	now := time.Now()
+	next := Status{CheckedAt: now}
+	if err != nil {
+		// Only an answer from ESI says anything about the server; a network
+		// error on our side leaves the state as it was.
+		var statusErr *esi.StatusError
+		if !errors.As(err, &statusErr) {
+			logger.Sugar.Warnf("Could not check server status: %v", err)
+			return
+		}
+		logger.Sugar.Debugf("Server status check failed: %v", err)
+	} else {
+		next = Status{
+			Online:        true,
+			Players:       resp.Players,
+			ServerVersion: resp.ServerVersion,
+			StartTime:     resp.StartTime,
+			VIP:           resp.VIP,
+			CheckedAt:     now,
+		}
+	}
+
+	s.mu.Lock()
+	previous, known := s.status, s.known
+	s.status, s.known = next, true
+	s.mu.Unlock()
+
+	if !known {
+		logger.Sugar.Infof("Tranquility online: %t, players: %d", next.Online, next.Players)
+		return
+	}
+	s.announce(previous, next)
+}
+
+// announce notifies about the difference between two consecutive checks.
+func (s *Service) announce(previous, next Status) {
+	var message string
+	switch {
+	case previous.Online && !next.Online:
+		message = "Tranquility is down."
+	case !previous.Online && next.Online && next.VIP:
+		message = "Tranquility is back up in VIP mode."
+	case !previous.Online && next.Online:
+		message = fmt.Sprintf("Tranquility is back online with %d players.", next.Players)
+	case previous.VIP && next.Online && !next.VIP:
+		message = "Tranquility VIP mode has ended."
+	default:
+		return
+	}
+	logger.Sugar.Infoln(message)
+	if s.configSvc.GetServerStatusAlerts() {
+		s.notifSvc.Notify("EVE Notify - Server Status", message, false)
+	}
+}
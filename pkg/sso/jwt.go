@@ -0,0 +1,221 @@
+package sso
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Claims are the parts of an EVE SSO access token we use.
+type Claims struct {
+	CharacterID   int64
+	CharacterName string
+	Scopes        []string
+	ExpiresAt     time.Time
+}
+
+// jwtHeader is the decoded header of a JWT.
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtPayload is the decoded payload of an EVE SSO JWT. "aud" and "scp" may be
+// either a single string or a list, so they are decoded by hand.
+type jwtPayload struct {
+	Sub  string          `json:"sub"`
+	Name string          `json:"name"`
+	Iss  string          `json:"iss"`
+	Exp  int64           `json:"exp"`
+	Aud  json.RawMessage `json:"aud"`
+	Scp  json.RawMessage `json:"scp"`
+}
+
+// jwk is one key of the SSO JSON Web Key Set.
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchKeys downloads the SSO signing keys.
+func (s *Service) fetchKeys(ctx context.Context, jwksURL string) ([]jwk, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SSO signing keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("SSO returned non-200 status for JWKS: %s", resp.Status)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode SSO signing keys: %w", err)
+	}
+	return set.Keys, nil
+}
+
+// validateToken checks the signature, issuer, audience and expiry of an access
+// token and returns its claims.
+func (s *Service) validateToken(ctx context.Context, ep endpoints, clientID, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("access token is not a JWT")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid JWT header: %w", err)
+	}
+	var payload jwtPayload
+	if err := decodeSegment(parts[1], &payload); err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT signature encoding: %w", err)
+	}
+
+	keys, err := s.fetchKeys(ctx, ep.JWKS)
+	if err != nil {
+		return nil, err
+	}
+	var key *jwk
+	for i := range keys {
+		if keys[i].Kid == header.Kid && keys[i].Alg == header.Alg {
+			key = &keys[i]
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("no SSO signing key matches kid %q and alg %q", header.Kid, header.Alg)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := verifySignature(key, digest[:], signature); err != nil {
+		return nil, err
+	}
+
+	// The issuer has been seen both with and without the scheme.
+	issuerHost := strings.TrimPrefix(strings.TrimPrefix(ep.Issuer, "https://"), "http://")
+	if strings.TrimPrefix(strings.TrimPrefix(payload.Iss, "https://"), "http://") != issuerHost {
+		return nil, fmt.Errorf("unexpected JWT issuer %q", payload.Iss)
+	}
+	audiences := stringOrList(payload.Aud)
+	if !contains(audiences, clientID) || !contains(audiences, "EVE Online") {
+		return nil, fmt.Errorf("JWT audience %v does not include this application", audiences)
+	}
+	expiresAt := time.Unix(payload.Exp, 0)
+	if time.Now().After(expiresAt) {
+		return nil, fmt.Errorf("access token expired at %s", expiresAt)
+	}
+
+	// The subject has the form "CHARACTER:EVE:<id>".
+	subject := strings.Split(payload.Sub, ":")
+	if len(subject) != 3 || subject[0] != "CHARACTER" {
+		return nil, fmt.Errorf("unexpected JWT subject %q", payload.Sub)
+	}
+	charID, err := strconv.ParseInt(subject[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid character ID in JWT subject %q", payload.Sub)
+	}
+
+	return &Claims{
+		CharacterID:   charID,
+		CharacterName: payload.Name,
+		Scopes:        stringOrList(payload.Scp),
+		ExpiresAt:     expiresAt,
+	}, nil
+}
+
+// verifySignature checks an RS256 or ES256 signature over a SHA-256 digest.
+func verifySignature(key *jwk, digest, signature []byte) error {
+	switch key.Alg {
+	case "RS256":
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature); err != nil {
+			return fmt.Errorf("invalid JWT signature: %w", err)
+		}
+		return nil
+	case "ES256":
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil {
+			return fmt.Errorf("invalid EC key: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(key.Y)
+		if err != nil {
+			return fmt.Errorf("invalid EC key: %w", err)
+		}
+		if len(signature) != 64 {
+			return fmt.Errorf("invalid ES256 signature length %d", len(signature))
+		}
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		r := new(big.Int).SetBytes(signature[:32])
+		sig := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest, r, sig) {
+			return fmt.Errorf("invalid JWT signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported JWT algorithm %q", key.Alg)
+	}
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// stringOrList decodes a JSON value that is either a string or a list of strings.
+func stringOrList(raw json.RawMessage) []string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil && single != "" {
+		return []string{single}
+	}
+	return nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
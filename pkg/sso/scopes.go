@@ -0,0 +1,21 @@
+package sso
+
+// Scope is an ESI scope the user can grant to eve-notify.
+type Scope struct {
+	Name        string
+	Description string
+}
+
+// KnownScopes lists the scopes offered when authorizing a character.
+var KnownScopes = []Scope{
+	{Name: "publicData", Description: "Public character information"},
+	{Name: "esi-characters.read_notifications.v1", Description: "In-game notifications (structures, wars, sovereignty)"},
+	{Name: "esi-skills.read_skillqueue.v1", Description: "Skill queue"},
+	{Name: "esi-industry.read_character_jobs.v1", Description: "Industry jobs"},
+	{Name: "esi-industry.read_corporation_jobs.v1", Description: "Corporation industry jobs"},
+	{Name: "esi-planets.manage_planets.v1", Description: "Planetary interaction colonies"},
+	{Name: "esi-markets.read_character_orders.v1", Description: "Market orders"},
+	{Name: "esi-mail.read_mail.v1", Description: "Mail"},
+	{Name: "esi-contracts.read_character_contracts.v1", Description: "Contracts"},
+	{Name: "esi-wallet.read_character_wallet.v1", Description: "Wallet journal"},
+}
@@ -0,0 +1,396 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// loginTimeout bounds how long we wait for the user to finish logging in.
+const loginTimeout = 5 * time.Minute
+
+// refreshMargin is how long before expiry an access token is refreshed.
+const refreshMargin = time.Minute
+
+var (
+	// ErrNotAuthorized is returned for characters without a stored authorization.
+	ErrNotAuthorized = errors.New("character has no ESI authorization")
+	// ErrRevoked is returned when SSO rejects a character's refresh token. The
+	// authorization is removed and the character has to be authorized again.
+	ErrRevoked = errors.New("ESI authorization was revoked; authorize the character again")
+)
+
+// endpoints are the SSO URLs derived from the configured SSO base URL.
+type endpoints struct {
+	Authorize string
+	Token     string
+	JWKS      string
+	Issuer    string
+}
+
+func endpointsFor(baseURL string) endpoints {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return endpoints{
+		Authorize: baseURL + "/v2/oauth/authorize",
+		Token:     baseURL + "/v2/oauth/token",
+		JWKS:      baseURL + "/oauth/jwks",
+		Issuer:    baseURL,
+	}
+}
+
+// Service implements the EVE SSO authorization-code flow with PKCE and keeps
+// the resulting tokens fresh. It is safe for concurrent use.
+type Service struct {
+	configSvc  *config.Service
+	httpClient *http.Client
+	store      *tokenStore
+
+	tokens     map[int64]*Token
+	refreshing map[int64]*sync.Mutex // Serializes refreshes per character.
+	listeners  []func(charID int64)
+	mu         sync.Mutex
+}
+
+// NewService creates the SSO service and loads stored tokens from dataDir.
+func NewService(cfg *config.Service, dataDir string) *Service {
+	s := &Service{
+		configSvc:  cfg,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		store:      newTokenStore(dataDir),
+		tokens:     make(map[int64]*Token),
+		refreshing: make(map[int64]*sync.Mutex),
+	}
+	tokens, err := s.store.load()
+	if err != nil {
+		logger.Sugar.Errorf("Failed to load ESI authorizations: %v", err)
+	} else {
+		s.tokens = tokens
+	}
+	logger.Sugar.Infof("Loaded ESI authorizations for %d characters.", len(s.tokens))
+	return s
+}
+
+// OnAuthorizationChanged registers a function that is called after a
+// character is authorized or its authorization is removed.
+func (s *Service) OnAuthorizationChanged(fn func(charID int64)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// CallbackURL is the redirect URL that must be registered for the application
+// on developers.eveonline.com.
+func (s *Service) CallbackURL() string {
+	return fmt.Sprintf("http://%s/callback", s.callbackAddress())
+}
+
+// callbackAddress is where the loopback listener binds. The redirect uses the
+// same literal address, as "localhost" may resolve to IPv6 only.
+func (s *Service) callbackAddress() string {
+	return fmt.Sprintf("127.0.0.1:%d", s.configSvc.GetSSOCallbackPort())
+}
+
+// Authorization returns a copy of a character's stored authorization.
+func (s *Service) Authorization(charID int64) (*Token, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[charID]
+	if !ok {
+		return nil, false
+	}
+	tokenCopy := *token
+	tokenCopy.Scopes = append([]string(nil), token.Scopes...)
+	return &tokenCopy, true
+}
+
+// Authorized returns the IDs of all characters with a stored authorization.
+func (s *Service) Authorized() []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]int64, 0, len(s.tokens))
+	for id := range s.tokens {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// HasScope reports whether a character granted a scope.
+func (s *Service) HasScope(charID int64, scope string) bool {
+	token, ok := s.Authorization(charID)
+	return ok && contains(token.Scopes, scope)
+}
+
+// Remove forgets a character's authorization.
+func (s *Service) Remove(charID int64) {
+	s.mu.Lock()
+	delete(s.tokens, charID)
+	if err := s.store.save(s.tokens); err != nil {
+		logger.Sugar.Errorf("Failed to save ESI authorizations: %v", err)
+	}
+	s.mu.Unlock()
+
+	logger.Sugar.Infof("Removed ESI authorization for character %d.", charID)
+	s.notifyListeners(charID)
+}
+
+// Login runs the authorization-code flow with PKCE. openURL is used to send the
+// user to the SSO login page; the answer arrives on a loopback listener.
+func (s *Service) Login(ctx context.Context, scopes []string, openURL func(*url.URL) error) (*Token, error) {
+	clientID := s.configSvc.GetSSOClientID()
+	if clientID == "" {
+		return nil, fmt.Errorf("no SSO client ID configured; register an application on developers.eveonline.com first")
+	}
+	ep := endpointsFor(s.configSvc.GetSSOBaseURL())
+
+	verifier, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	state, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	listener, err := net.Listen("tcp", s.callbackAddress())
+	if err != nil {
+		return nil, fmt.Errorf("could not listen for the SSO callback: %w", err)
+	}
+	codes := make(chan string, 1)
+	failures := make(chan error, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			http.Error(w, "State mismatch, please try again.", http.StatusBadRequest)
+			sendOnce(failures, fmt.Errorf("SSO callback state mismatch"))
+			return
+		}
+		code := query.Get("code")
+		if code == "" {
+			http.Error(w, "Login was cancelled.", http.StatusBadRequest)
+			sendOnce(failures, fmt.Errorf("SSO login failed: %s", query.Get("error")))
+			return
+		}
+		fmt.Fprintln(w, "EVE Notify is now authorized. You can close this window.")
+		sendOnce(codes, code)
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	authURL, err := url.Parse(ep.Authorize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSO base URL: %w", err)
+	}
+	authURL.RawQuery = url.Values{
+		"response_type":         {"code"},
+		"redirect_uri":          {s.CallbackURL()},
+		"client_id":             {clientID},
+		"scope":                 {strings.Join(scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+	logger.Sugar.Infof("Starting SSO login with scopes: %v", scopes)
+	if err := openURL(authURL); err != nil {
+		return nil, fmt.Errorf("could not open the SSO login page: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
+	var code string
+	select {
+	case code = <-codes:
+	case err := <-failures:
+		return nil, err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("SSO login timed out: %w", ctx.Err())
+	}
+
+	token, err := s.requestToken(ctx, ep, clientID, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"client_id":     {clientID},
+		"code_verifier": {verifier},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.tokens[token.CharacterID] = token
+	if err := s.store.save(s.tokens); err != nil {
+		logger.Sugar.Errorf("Failed to save ESI authorizations: %v", err)
+	}
+	s.mu.Unlock()
+
+	logger.Sugar.Infof("Authorized character %s (%d) with %d scopes.", token.CharacterName, token.CharacterID, len(token.Scopes))
+	s.notifyListeners(token.CharacterID)
+	tokenCopy := *token
+	return &tokenCopy, nil
+}
+
+// AccessToken returns a valid access token for a character, refreshing it
+// first if it is about to expire. Rotated refresh tokens are stored. A revoked
+// authorization is removed and reported as ErrRevoked.
+func (s *Service) AccessToken(ctx context.Context, charID int64) (string, error) {
+	token, ok := s.currentToken(charID)
+	if !ok {
+		return "", ErrNotAuthorized
+	}
+	if time.Until(token.ExpiresAt) > refreshMargin {
+		return token.AccessToken, nil
+	}
+
+	// Refreshes of one character are serialized so a rotated refresh token is
+	// never used twice; s.mu is not held across the request.
+	s.mu.Lock()
+	refreshLock, ok := s.refreshing[charID]
+	if !ok {
+		refreshLock = &sync.Mutex{}
+		s.refreshing[charID] = refreshLock
+	}
+	s.mu.Unlock()
+	refreshLock.Lock()
+	defer refreshLock.Unlock()
+
+	// Another caller may have refreshed the token while we waited.
+	token, ok = s.currentToken(charID)
+	if !ok {
+		return "", ErrNotAuthorized
+	}
+	if time.Until(token.ExpiresAt) > refreshMargin {
+		return token.AccessToken, nil
+	}
+
+	clientID := s.configSvc.GetSSOClientID()
+	logger.Sugar.Debugf("Refreshing access token for character %d.", charID)
+	refreshed, err := s.requestToken(ctx, endpointsFor(s.configSvc.GetSSOBaseURL()), clientID, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {token.RefreshToken},
+		"client_id":     {clientID},
+	})
+	if errors.Is(err, ErrRevoked) {
+		logger.Sugar.Warnf("ESI authorization for character %d was revoked.", charID)
+		s.Remove(charID)
+		return "", fmt.Errorf("could not refresh access token for character %d: %w", charID, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("could not refresh access token for character %d: %w", charID, err)
+	}
+	if refreshed.CharacterID != charID {
+		return "", fmt.Errorf("refreshed token belongs to character %d, expected %d", refreshed.CharacterID, charID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A login or removal during the refresh wins over the refreshed token.
+	if current, ok := s.tokens[charID]; !ok || current.RefreshToken != token.RefreshToken {
+		return refreshed.AccessToken, nil
+	}
+	s.tokens[charID] = refreshed
+	if err := s.store.save(s.tokens); err != nil {
+		logger.Sugar.Errorf("Failed to save ESI authorizations: %v", err)
+	}
+	return refreshed.AccessToken, nil
+}
+
+// currentToken returns a copy of a character's stored token.
+func (s *Service) currentToken(charID int64) (Token, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[charID]
+	if !ok {
+		return Token{}, false
+	}
+	return *token, true
+}
+
+// requestToken posts a grant to the token endpoint and validates the answer.
+func (s *Service) requestToken(ctx context.Context, ep endpoints, clientID string, form url.Values) (*Token, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.Token, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make token request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&failure) == nil && failure.Error == "invalid_grant" {
+			return nil, ErrRevoked
+		}
+		return nil, fmt.Errorf("SSO returned non-200 status for token request: %s", resp.Status)
+	}
+
+	var tokenResp struct {
+		AccessToken  string `json:"access_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	claims, err := s.validateToken(ctx, ep, clientID, tokenResp.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	return &Token{
+		CharacterID:   claims.CharacterID,
+		CharacterName: claims.CharacterName,
+		AccessToken:   tokenResp.AccessToken,
+		RefreshToken:  tokenResp.RefreshToken,
+		ExpiresAt:     claims.ExpiresAt,
+		Scopes:        claims.Scopes,
+	}, nil
+}
+
+func (s *Service) notifyListeners(charID int64) {
+	s.mu.Lock()
+	listeners := append([]func(int64){}, s.listeners...)
+	s.mu.Unlock()
+	for _, fn := range listeners {
+		fn(charID)
+	}
+}
+
+// sendOnce delivers a value without blocking if one is already waiting.
+func sendOnce[T any](ch chan T, value T) {
+	select {
+	case ch <- value:
+	default:
+	}
+}
+
+// randomString returns n random bytes encoded as unpadded base64url.
+func randomString(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("could not generate random data: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,415 @@
+package sso
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+const (
+	testClientID = "test-client"
+	testCharID   = 90000001
+)
+
+func TestMain(m *testing.M) {
+	sync := logger.Init()
+	code := m.Run()
+	sync()
+	os.Exit(code)
+}
+
+// mockSSO is a stand-in for the EVE SSO with a JWKS and a token endpoint that
+// rotates refresh tokens.
+type mockSSO struct {
+	t      *testing.T
+	server *httptest.Server
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+
+	mu            sync.Mutex
+	challenge     string // The PKCE challenge of the last authorization.
+	refreshTokens map[string]bool
+	refreshes     int
+	issued        int
+}
+
+func newMockSSO(t *testing.T) *mockSSO {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockSSO{t: t, rsaKey: rsaKey, ecKey: ecKey, refreshTokens: make(map[string]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/jwks", m.jwks)
+	mux.HandleFunc("/v2/oauth/token", m.token)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockSSO) jwks(w http.ResponseWriter, r *http.Request) {
+	encode := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+	keys := []jwk{
+		{Kid: "rs", Kty: "RSA", Alg: "RS256", N: encode(m.rsaKey.N), E: encode(big.NewInt(int64(m.rsaKey.E)))},
+		{Kid: "es", Kty: "EC", Alg: "ES256", Crv: "P-256", X: encode(m.ecKey.X), Y: encode(m.ecKey.Y)},
+	}
+	_ = json.NewEncoder(w).Encode(map[string][]jwk{"keys": keys})
+}
+
+func (m *mockSSO) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		m.t.Errorf("parse token request: %v", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "the-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+	case "refresh_token":
+		m.refreshes++
+		if !m.refreshTokens[r.PostForm.Get("refresh_token")] {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		// Refresh tokens are single use.
+		delete(m.refreshTokens, r.PostForm.Get("refresh_token"))
+	default:
+		http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+
+	m.issued++
+	refresh := fmt.Sprintf("refresh-%d", m.issued)
+	m.refreshTokens[refresh] = true
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  m.jwt("rs", m.claims(nil)),
+		"expires_in":    1199,
+		"refresh_token": refresh,
+	})
+}
+
+// claims returns valid access token claims with changes applied.
+func (m *mockSSO) claims(change func(map[string]interface{})) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub":  fmt.Sprintf("CHARACTER:EVE:%d", testCharID),
+		"name": "Test Pilot",
+		"iss":  strings.TrimPrefix(m.server.URL, "http://"),
+		"exp":  time.Now().Add(20 * time.Minute).Unix(),
+		"aud":  []string{testClientID, "EVE Online"},
+		"scp":  []string{"esi-mail.read_mail.v1", "esi-wallet.read_character_wallet.v1"},
+	}
+	if change != nil {
+		change(claims)
+	}
+	return claims
+}
+
+// jwt signs claims with the key of kid.
+func (m *mockSSO) jwt(kid string, claims map[string]interface{}) string {
+	alg := map[string]string{"rs": "RS256", "es": "ES256"}[kid]
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch kid {
+	case "rs":
+		sig, err := rsa.SignPKCS1v15(rand.Reader, m.rsaKey, crypto.SHA256, digest[:])
+		if err != nil {
+			m.t.Fatal(err)
+		}
+		signature = sig
+	case "es":
+		r, s, err := ecdsa.Sign(rand.Reader, m.ecKey, digest[:])
+		if err != nil {
+			m.t.Fatal(err)
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// newTestService returns a service configured against the mock SSO.
+func newTestService(t *testing.T, mock *mockSSO) *Service {
+	cfg := config.NewService(test.NewApp())
+	cfg.SetSSOBaseURL(mock.server.URL)
+	cfg.SetSSOClientID(testClientID)
+	cfg.SetSSOCallbackPort(freePort(t))
+	return NewService(cfg, t.TempDir())
+}
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestLoginExchangesCodeWithPKCE(t *testing.T) {
+	mock := newMockSSO(t)
+	svc := newTestService(t, mock)
+
+	openURL := func(authURL *url.URL) error {
+		query := authURL.Query()
+		if query.Get("code_challenge_method") != "S256" || query.Get("client_id") != testClientID {
+			t.Errorf("unexpected authorization query %v", query)
+		}
+		if redirect := query.Get("redirect_uri"); redirect != svc.CallbackURL() || !strings.HasPrefix(redirect, "http://127.0.0.1:") {
+			t.Errorf("redirect_uri = %q, want the 127.0.0.1 callback", redirect)
+		}
+		mock.mu.Lock()
+		mock.challenge = query.Get("code_challenge")
+		mock.mu.Unlock()
+
+		// Play the browser being redirected back.
+		callback := fmt.Sprintf("%s?code=the-code&state=%s", query.Get("redirect_uri"), url.QueryEscape(query.Get("state")))
+		resp, err := http.Get(callback)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	token, err := svc.Login(context.Background(), []string{"esi-mail.read_mail.v1"}, openURL)
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if token.CharacterID != testCharID || token.CharacterName != "Test Pilot" || len(token.Scopes) != 2 {
+		t.Errorf("Login = %+v", token)
+	}
+	if !svc.HasScope(testCharID, "esi-mail.read_mail.v1") {
+		t.Error("authorized character is missing its scope")
+	}
+}
+
+func TestLoginRejectsStateMismatch(t *testing.T) {
+	mock := newMockSSO(t)
+	svc := newTestService(t, mock)
+
+	openURL := func(authURL *url.URL) error {
+		resp, err := http.Get(authURL.Query().Get("redirect_uri") + "?code=the-code&state=forged")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+	if _, err := svc.Login(context.Background(), nil, openURL); err == nil {
+		t.Fatal("Login accepted a callback with a forged state")
+	}
+	if len(svc.Authorized()) != 0 {
+		t.Error("forged login stored an authorization")
+	}
+}
+
+func TestValidateToken(t *testing.T) {
+	mock := newMockSSO(t)
+	svc := newTestService(t, mock)
+	ep := endpointsFor(mock.server.URL)
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := &mockSSO{t: t, rsaKey: other, server: mock.server}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{"RS256", mock.jwt("rs", mock.claims(nil)), ""},
+		{"ES256", mock.jwt("es", mock.claims(nil)), ""},
+		{"issuer with scheme", mock.jwt("rs", mock.claims(func(c map[string]interface{}) { c["iss"] = mock.server.URL })), ""},
+		{"audience without EVE Online", mock.jwt("rs", mock.claims(func(c map[string]interface{}) { c["aud"] = testClientID })), "audience"},
+		{"wrong issuer", mock.jwt("rs", mock.claims(func(c map[string]interface{}) { c["iss"] = "login.example.com" })), "issuer"},
+		{"wrong audience", mock.jwt("rs", mock.claims(func(c map[string]interface{}) { c["aud"] = []string{"other", "EVE Online"} })), "audience"},
+		{"expired", mock.jwt("rs", mock.claims(func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Minute).Unix() })), "expired"},
+		{"bad subject", mock.jwt("rs", mock.claims(func(c map[string]interface{}) { c["sub"] = "CORPORATION:EVE:1" })), "subject"},
+		{"wrong key", forged.jwt("rs", mock.claims(nil)), "signature"},
+		{"not a JWT", "opaque-token", "not a JWT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := svc.validateToken(context.Background(), ep, testClientID, tt.token)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateToken: %v", err)
+				}
+				if claims.CharacterID != testCharID || len(claims.Scopes) != 2 {
+					t.Errorf("claims = %+v", claims)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateToken error = %v, want one about %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// authorize stores a token for the test character that is about to expire.
+func authorize(mock *mockSSO, svc *Service, refresh string) {
+	mock.mu.Lock()
+	mock.refreshTokens[refresh] = true
+	mock.mu.Unlock()
+	svc.tokens[testCharID] = &Token{
+		CharacterID:  testCharID,
+		AccessToken:  "stale",
+		RefreshToken: refresh,
+		ExpiresAt:    time.Now().Add(10 * time.Second),
+	}
+}
+
+func TestAccessTokenRotatesRefreshTokensOnce(t *testing.T) {
+	mock := newMockSSO(t)
+	svc := newTestService(t, mock)
+	authorize(mock, svc, "first")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.AccessToken(context.Background(), testCharID); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("AccessToken: %v", err)
+	}
+
+	if mock.refreshes != 1 {
+		t.Errorf("SSO saw %d refreshes, want 1", mock.refreshes)
+	}
+	token, _ := svc.Authorization(testCharID)
+	if token.RefreshToken == "first" || token.AccessToken == "stale" || time.Until(token.ExpiresAt) < 10*time.Minute {
+		t.Errorf("token was not rotated: %+v", token)
+	}
+
+	// The rotated token is stored encrypted and survives a restart.
+	reloaded, err := svc.store.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded[testCharID].RefreshToken != token.RefreshToken {
+		t.Errorf("stored refresh token = %q, want %q", reloaded[testCharID].RefreshToken, token.RefreshToken)
+	}
+}
+
+func TestAccessTokenDropsRevokedAuthorization(t *testing.T) {
+	mock := newMockSSO(t)
+	svc := newTestService(t, mock)
+	authorize(mock, svc, "first")
+	mock.mu.Lock()
+	delete(mock.refreshTokens, "first")
+	mock.mu.Unlock()
+
+	var changed []int64
+	svc.OnAuthorizationChanged(func(charID int64) { changed = append(changed, charID) })
+
+	if _, err := svc.AccessToken(context.Background(), testCharID); !errors.Is(err, ErrRevoked) {
+		t.Fatalf("AccessToken error = %v, want ErrRevoked", err)
+	}
+	if _, ok := svc.Authorization(testCharID); ok {
+		t.Error("revoked authorization was kept")
+	}
+	if len(changed) != 1 || changed[0] != testCharID {
+		t.Errorf("listeners saw %v, want the revoked character", changed)
+	}
+	if _, err := svc.AccessToken(context.Background(), testCharID); !errors.Is(err, ErrNotAuthorized) {
+		t.Errorf("AccessToken after revocation = %v, want ErrNotAuthorized", err)
+	}
+	if mock.refreshes != 1 {
+		t.Errorf("SSO saw %d refreshes, want 1", mock.refreshes)
+	}
+}
+
+func TestTokenStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := newTokenStore(dir)
+	tokens := map[int64]*Token{
+		testCharID: {
+			CharacterID:   testCharID,
+			CharacterName: "Test Pilot",
+			AccessToken:   "access-secret",
+			RefreshToken:  "refresh-secret",
+			ExpiresAt:     time.Now().Add(time.Hour).Round(time.Second),
+			Scopes:        []string{"esi-mail.read_mail.v1"},
+		},
+	}
+	if err := store.save(tokens); err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := os.ReadFile(filepath.Join(dir, "sso_tokens.enc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(sealed), "secret") {
+		t.Error("token store holds tokens in plaintext")
+	}
+	if info, err := os.Stat(filepath.Join(dir, "sso.key")); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v, %v; want 0600", info, err)
+	}
+
+	loaded, err := newTokenStore(dir).load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := loaded[testCharID]
+	want := tokens[testCharID]
+	if got == nil || got.RefreshToken != want.RefreshToken || !got.ExpiresAt.Equal(want.ExpiresAt) || len(got.Scopes) != 1 {
+		t.Errorf("loaded %+v, want %+v", got, want)
+	}
+
+	// A different key cannot open the store.
+	if err := os.WriteFile(filepath.Join(dir, "sso.key"), make([]byte, 32), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newTokenStore(dir).load(); err == nil {
+		t.Error("token store opened with the wrong key")
+	}
+}
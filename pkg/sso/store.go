@@ -0,0 +1,114 @@
+package sso
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Token is the stored authorization of one character.
+type Token struct {
+	CharacterID   int64
+	CharacterName string
+	AccessToken   string
+	RefreshToken  string
+	ExpiresAt     time.Time
+	Scopes        []string
+}
+
+// tokenStore keeps tokens on disk, encrypted with AES-256-GCM. The key lives
+// in its own file next to the tokens, readable only by the current user.
+type tokenStore struct {
+	tokensPath string
+	keyPath    string
+}
+
+func newTokenStore(dataDir string) *tokenStore {
+	return &tokenStore{
+		tokensPath: filepath.Join(dataDir, "sso_tokens.enc"),
+		keyPath:    filepath.Join(dataDir, "sso.key"),
+	}
+}
+
+// load reads and decrypts all stored tokens. A missing file is not an error.
+func (t *tokenStore) load() (map[int64]*Token, error) {
+	tokens := make(map[int64]*Token)
+	sealed, err := os.ReadFile(t.tokensPath)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read token store: %w", err)
+	}
+
+	aead, err := t.cipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("token store is truncated")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt token store: %w", err)
+	}
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return nil, fmt.Errorf("could not decode token store: %w", err)
+	}
+	return tokens, nil
+}
+
+// save encrypts and writes all tokens.
+func (t *tokenStore) save(tokens map[int64]*Token) error {
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("could not encode tokens: %w", err)
+	}
+	aead, err := t.cipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("could not generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plain, nil)
+
+	tmpPath := t.tokensPath + ".tmp"
+	if err := os.WriteFile(tmpPath, sealed, 0o600); err != nil {
+		return fmt.Errorf("could not write token store: %w", err)
+	}
+	return os.Rename(tmpPath, t.tokensPath)
+}
+
+// cipher returns the AEAD for the store key, creating the key on first use.
+func (t *tokenStore) cipher() (cipher.AEAD, error) {
+	key, err := os.ReadFile(t.keyPath)
+	if os.IsNotExist(err) {
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, fmt.Errorf("could not generate token key: %w", err)
+		}
+		if err := os.WriteFile(t.keyPath, key, 0o600); err != nil {
+			return nil, fmt.Errorf("could not write token key: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("could not read token key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("token key has invalid length %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not create token cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
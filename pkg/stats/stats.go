@@ -0,0 +1,25 @@
+package stats
+
+import "time"
+
+// Session is the statistics of one client session, identified by when its
+// gamelog was started.
+type Session interface {
+	StartedAt() time.Time
+}
+
+// Current returns the latest of a character's sessions, oldest first, if it
+// is the one started at started. Otherwise it appends the session made by
+// create and drops the oldest beyond limit. The sessions are returned as
+// well, as appending may move them.
+func Current[S Session](sessions []S, started time.Time, limit int, create func() S) (S, []S) {
+	if n := len(sessions); n > 0 && sessions[n-1].StartedAt().Equal(started) {
+		return sessions[n-1], sessions
+	}
+	session := create()
+	sessions = append(sessions, session)
+	if len(sessions) > limit {
+		sessions = sessions[len(sessions)-limit:]
+	}
+	return session, sessions
+}
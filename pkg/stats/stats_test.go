@@ -0,0 +1,38 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+type testSession struct {
+	Started time.Time
+	Count   int
+}
+
+func (s *testSession) StartedAt() time.Time {
+	return s.Started
+}
+
+func TestCurrent(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	var sessions []*testSession
+	record := func(started time.Time) {
+		var session *testSession
+		session, sessions = Current(sessions, started, 2, func() *testSession {
+			return &testSession{Started: started}
+		})
+		session.Count++
+	}
+
+	record(base)
+	record(base)
+	if len(sessions) != 1 || sessions[0].Count != 2 {
+		t.Fatalf("same start did not reuse the session: %+v", sessions)
+	}
+	record(base.Add(time.Hour))
+	record(base.Add(2 * time.Hour))
+	if len(sessions) != 2 || !sessions[0].Started.Equal(base.Add(time.Hour)) || !sessions[1].Started.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("sessions beyond the limit were not dropped oldest first: %+v", sessions)
+	}
+}
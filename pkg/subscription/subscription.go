@@ -12,9 +12,53 @@ type NotificationSettings struct {
 	CorpChat          bool
 	LocalChat         bool
 	MiningStorageFull bool
+	CargoFullSoon     bool // Ore hold expected to fill within the warning time.
+	MiningIdle        bool // Mining cycles stopped, an asteroid depleted or a module deactivated.
+	BountySummary     bool // Bounty income summary when a ratting session ends.
+	IncomingDPS       bool // Incoming damage at or above the configured DPS.
 	NpcAggression     bool
 	PlayerAggression  bool
 	ManualAutopilot   bool
+	AutopilotRoute    bool // Arrival and stuck alerts for the destination set on the character.
+	SessionEvents     bool // Login and disconnect notices.
+	ClientIdle        bool // Gamelog silent for longer than the idle threshold.
+	SecurityDrop      bool // Entering space below SecurityThreshold, Pochven or a wormhole.
+	IntelReports      bool // Hostiles reported in intel channels within the intel range.
+	// SecurityThreshold is the security status below which SecurityDrop
+	// alerts; nil means DefaultSecurityThreshold. A pointer, as 0 is a
+	// threshold of its own.
+	SecurityThreshold *float64 `json:",omitempty"`
+
+	// In-game notifications read from ESI; these need an ESI authorization.
+	StructureAttacks   bool
+	StructureFuel      bool
+	WarDeclarations    bool
+	SovereigntyChanges bool
+	SkillCompleted     bool
+	SkillQueueLow      bool // Queue runs empty within the warning horizon, or already is.
+	IndustryJobs       bool
+	IndustryCorpJobs   bool // Also corporation jobs; needs a factory manager role.
+	PlanetExtractors   bool // Extractors about to stop or stopped.
+	PlanetStorage      bool // Launchpads and storage about to fill.
+	MarketFilled       bool
+	MarketPartialFill  bool // Every fill step set in the app settings.
+	MarketOutbid       bool // Outbid or undercut at the same station.
+	MarketExpiry       bool
+	MailReceived       bool // New mail with one of the labels set in the app settings.
+	Contracts          bool // Contracts assigned to the character, or its own accepted or completed.
+	WalletJournal      bool // Income above the threshold set in the app settings.
+}
+
+// DefaultSecurityThreshold alerts on leaving high-sec.
+const DefaultSecurityThreshold = 0.5
+
+// SecurityThresholdOrDefault returns SecurityThreshold, or the default if it
+// was never set.
+func (s *NotificationSettings) SecurityThresholdOrDefault() float64 {
+	if s.SecurityThreshold == nil {
+		return DefaultSecurityThreshold
+	}
+	return *s.SecurityThreshold
 }
 
 // Service manages the subscription state for all characters. It's thread-safe.
@@ -0,0 +1,39 @@
+package subscription
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSecurityThresholdOrDefault(t *testing.T) {
+	zero, low := 0.0, 0.3
+	tests := []struct {
+		name      string
+		threshold *float64
+		want      float64
+	}{
+		{"unset", nil, DefaultSecurityThreshold},
+		{"zero", &zero, 0},
+		{"set", &low, 0.3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := NotificationSettings{SecurityThreshold: tt.threshold}
+			if got := settings.SecurityThresholdOrDefault(); got != tt.want {
+				t.Errorf("SecurityThresholdOrDefault() = %v, want %v", got, tt.want)
+			}
+
+			raw, err := json.Marshal(settings)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded NotificationSettings
+			if err := json.Unmarshal(raw, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if got := decoded.SecurityThresholdOrDefault(); got != tt.want {
+				t.Errorf("after a JSON round trip, SecurityThresholdOrDefault() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
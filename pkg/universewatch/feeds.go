@@ -0,0 +1,191 @@
+package universewatch
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+)
+
+// incursion is one entry of GET /incursions/.
+type incursion struct {
+	ConstellationID      int64  `json:"constellation_id"`
+	StagingSolarSystemID int64  `json:"staging_solar_system_id"`
+	State                string `json:"state"`
+	HasBoss              bool   `json:"has_boss"`
+}
+
+// campaign is one entry of GET /sovereignty/campaigns/.
+type campaign struct {
+	CampaignID    int64  `json:"campaign_id"`
+	EventType     string `json:"event_type"`
+	SolarSystemID int64  `json:"solar_system_id"`
+	DefenderID    int64  `json:"defender_id"`
+}
+
+// fwSystem is one entry of GET /fw/systems/.
+type fwSystem struct {
+	SolarSystemID     int64  `json:"solar_system_id"`
+	OccupierFactionID int64  `json:"occupier_faction_id"`
+	Contested         string `json:"contested"`
+}
+
+// fwState is what is compared between faction warfare snapshots.
+type fwState struct {
+	Occupier  int64
+	Contested string
+}
+
+// campaignEvents names sovereignty campaign event types.
+var campaignEvents = map[string]string{
+	"tcu_defense":             "TCU defense",
+	"ihub_defense":            "IHub defense",
+	"station_defense":         "Station defense",
+	"station_freeport":        "Station freeport",
+	"sovereignty_hub_defense": "Sovereignty hub defense",
+}
+
+// pollIncursions reports incursions that spawned or changed state in filtered
+// places. It returns whether the snapshot changed.
+func (s *Service) pollIncursions(ctx context.Context, filter []string) bool {
+	var incursions []incursion
+	if _, err := s.esiClient.Get(ctx, "/incursions/", &incursions); err != nil {
+		logger.Sugar.Warnf("Could not read incursions: %v", err)
+		return false
+	}
+
+	current := make(map[int64]string, len(incursions))
+	for _, inc := range incursions {
+		current[inc.ConstellationID] = inc.State
+		if s.previous.Incursions == nil {
+			continue
+		}
+		before, existed := s.previous.Incursions[inc.ConstellationID]
+		if existed && before == inc.State {
+			continue
+		}
+		names := s.location(ctx, inc.StagingSolarSystemID, inc.ConstellationID)
+		if names == nil {
+			// Keep the old state, so the change is reported on the next poll.
+			if existed {
+				current[inc.ConstellationID] = before
+			} else {
+				delete(current, inc.ConstellationID)
+			}
+			continue
+		}
+		if !matches(filter, names) {
+			continue
+		}
+		where := strings.Join(names, ", ")
+		message := fmt.Sprintf("New incursion staging in %s.", where)
+		if existed {
+			message = fmt.Sprintf("Incursion staging in %s is now %s.", where, inc.State)
+		}
+		s.notify("EVE Notify - Incursion", message)
+	}
+	if s.previous.Incursions != nil {
+		for constellationID, state := range s.previous.Incursions {
+			if _, ok := current[constellationID]; ok {
+				continue
+			}
+			names := s.location(ctx, 0, constellationID)
+			if names == nil {
+				current[constellationID] = state
+				continue
+			}
+			if matches(filter, names) {
+				s.notify("EVE Notify - Incursion", fmt.Sprintf("Incursion in %s has ended.", strings.Join(names, ", ")))
+			}
+		}
+	}
+	changed := s.previous.Incursions == nil || !maps.Equal(s.previous.Incursions, current)
+	s.previous.Incursions = current
+	return changed
+}
+
+// pollCampaigns reports sovereignty campaigns starting in filtered places.
+// It returns whether the snapshot changed.
+func (s *Service) pollCampaigns(ctx context.Context, filter []string) bool {
+	var campaigns []campaign
+	if _, err := s.esiClient.Get(ctx, "/sovereignty/campaigns/", &campaigns); err != nil {
+		logger.Sugar.Warnf("Could not read sovereignty campaigns: %v", err)
+		return false
+	}
+
+	current := make(map[int64]bool, len(campaigns))
+	for _, c := range campaigns {
+		current[c.CampaignID] = true
+		if s.previous.Campaigns == nil || s.previous.Campaigns[c.CampaignID] {
+			continue
+		}
+		names := s.location(ctx, c.SolarSystemID, 0)
+		if names == nil {
+			// Left out, so the campaign is still new on the next poll.
+			delete(current, c.CampaignID)
+			continue
+		}
+		if !matches(filter, names) {
+			continue
+		}
+		event, ok := campaignEvents[c.EventType]
+		if !ok {
+			event = c.EventType
+		}
+		message := fmt.Sprintf("%s campaign in %s", event, strings.Join(names, ", "))
+		if defender := s.name(ctx, c.DefenderID); defender != "" {
+			message += fmt.Sprintf(" against %s", defender)
+		}
+		s.notify("EVE Notify - Sovereignty", message+".")
+	}
+	changed := s.previous.Campaigns == nil || !maps.Equal(s.previous.Campaigns, current)
+	s.previous.Campaigns = current
+	return changed
+}
+
+// pollFactionWarfare reports filtered faction warfare systems whose contest
+// state or occupier changed. It returns whether the snapshot changed.
+func (s *Service) pollFactionWarfare(ctx context.Context, filter []string) bool {
+	var systems []fwSystem
+	if _, err := s.esiClient.Get(ctx, "/fw/systems/", &systems); err != nil {
+		logger.Sugar.Warnf("Could not read faction warfare systems: %v", err)
+		return false
+	}
+
+	current := make(map[int64]fwState, len(systems))
+	for _, system := range systems {
+		state := fwState{Occupier: system.OccupierFactionID, Contested: system.Contested}
+		current[system.SolarSystemID] = state
+		if s.previous.FWSystems == nil {
+			continue
+		}
+		before, existed := s.previous.FWSystems[system.SolarSystemID]
+		if !existed || before == state {
+			continue
+		}
+		names := s.location(ctx, system.SolarSystemID, 0)
+		if names == nil {
+			// Keep the old state, so the change is reported on the next poll.
+			current[system.SolarSystemID] = before
+			continue
+		}
+		if !matches(filter, names) {
+			continue
+		}
+		message := fmt.Sprintf("%s is now %s.", names[0], system.Contested)
+		if before.Occupier != state.Occupier {
+			message = fmt.Sprintf("%s was captured by %s.", names[0], s.name(ctx, state.Occupier))
+		}
+		s.notify("EVE Notify - Faction Warfare", message)
+	}
+	changed := s.previous.FWSystems == nil || !maps.Equal(s.previous.FWSystems, current)
+	s.previous.FWSystems = current
+	return changed
+}
+
+func (s *Service) notify(title, message string) {
+	logger.Sugar.Infoln(message)
+	s.notifSvc.Notify(title, message, false)
+}
@@ -0,0 +1,210 @@
+package universewatch
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FabricSoul/eve-notify/pkg/config"
+	"github.com/FabricSoul/eve-notify/pkg/esi"
+	"github.com/FabricSoul/eve-notify/pkg/jsonfile"
+	"github.com/FabricSoul/eve-notify/pkg/logger"
+	"github.com/FabricSoul/eve-notify/pkg/notification"
+)
+
+// pollInterval is how often the public feeds are compared. Incursions and
+// sovereignty campaigns are cached by ESI for a few minutes.
+const pollInterval = 5 * time.Minute
+
+// snapshot is the state of the public feeds at the last poll. A nil map means
+// the feed was never read, so its first read is only recorded.
+type snapshot struct {
+	Incursions map[int64]string  // constellation ID -> incursion state
+	Campaigns  map[int64]bool    // campaign ID
+	FWSystems  map[int64]fwState // solar system ID -> contest state
+}
+
+// Service watches public ESI feeds (incursions, sovereignty campaigns and
+// faction warfare) for changes in the places the user cares about. It does
+// not depend on any character being subscribed.
+type Service struct {
+	configSvc *config.Service
+	esiClient *esi.Client
+	notifSvc  *notification.Service
+
+	path     string
+	previous snapshot
+
+	// locations caches the system, constellation and region names of an ID.
+	locations map[int64][]string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewService creates the universe watch service. The last snapshot is kept in
+// dataDir so changes that happen while the app is closed are still reported.
+func NewService(cfg *config.Service, esiClient *esi.Client, notifSvc *notification.Service, dataDir string) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		configSvc: cfg,
+		esiClient: esiClient,
+		notifSvc:  notifSvc,
+		path:      filepath.Join(dataDir, "universe_snapshot.json"),
+		locations: make(map[int64][]string),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	s.load()
+	return s
+}
+
+// Start runs the polling loop. It should be run in a goroutine.
+func (s *Service) Start() {
+	logger.Sugar.Infoln("Universe watch service started.")
+	s.wg.Add(1)
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	s.poll()
+	for {
+		select {
+		case <-ticker.C:
+			s.poll()
+		case <-s.ctx.Done():
+			logger.Sugar.Infoln("Universe watch service shutting down.")
+			return
+		}
+	}
+}
+
+// Stop ends the polling loop and waits for it to finish.
+func (s *Service) Stop() {
+	logger.Sugar.Infoln("Stopping universe watch service...")
+	s.cancel()
+	s.wg.Wait()
+	logger.Sugar.Infoln("Universe watch service stopped.")
+}
+
+// poll reads every feed that has a filter and notifies about changes. The
+// snapshot of a feed without a filter is dropped, so turning it back on only
+// primes it instead of reporting everything that changed in between.
+func (s *Service) poll() {
+	ctx, cancel := context.WithTimeout(s.ctx, 2*time.Minute)
+	defer cancel()
+
+	changed := false
+	if filter := parseFilter(s.configSvc.GetIncursionFilter()); len(filter) > 0 {
+		changed = s.pollIncursions(ctx, filter) || changed
+	} else if s.previous.Incursions != nil {
+		s.previous.Incursions = nil
+		changed = true
+	}
+	if filter := parseFilter(s.configSvc.GetSovCampaignFilter()); len(filter) > 0 {
+		changed = s.pollCampaigns(ctx, filter) || changed
+	} else if s.previous.Campaigns != nil {
+		s.previous.Campaigns = nil
+		changed = true
+	}
+	if filter := parseFilter(s.configSvc.GetFWFilter()); len(filter) > 0 {
+		changed = s.pollFactionWarfare(ctx, filter) || changed
+	} else if s.previous.FWSystems != nil {
+		s.previous.FWSystems = nil
+		changed = true
+	}
+	if changed {
+		s.save()
+	}
+}
+
+// location returns the names of a solar system or constellation and of the
+// constellation and region it is in, for matching against filters. It returns
+// nil if any of them could not be looked up.
+func (s *Service) location(ctx context.Context, systemID, constellationID int64) []string {
+	key := systemID
+	if key == 0 {
+		key = constellationID
+	}
+	if names, ok := s.locations[key]; ok {
+		return names
+	}
+
+	var names []string
+	if systemID != 0 {
+		system, err := s.esiClient.GetSystem(ctx, systemID)
+		if err != nil {
+			logger.Sugar.Warnf("Could not look up system %d: %v", systemID, err)
+			return nil
+		}
+		names = append(names, system.Name)
+		constellationID = system.ConstellationID
+	}
+	constellation, err := s.esiClient.GetConstellation(ctx, constellationID)
+	if err != nil {
+		logger.Sugar.Warnf("Could not look up constellation %d: %v", constellationID, err)
+		return nil
+	}
+	names = append(names, constellation.Name)
+	region, err := s.esiClient.ResolveNames(ctx, []int64{constellation.RegionID})
+	if err != nil {
+		logger.Sugar.Warnf("Could not look up region %d: %v", constellation.RegionID, err)
+		return nil
+	}
+	if entry, ok := region[constellation.RegionID]; ok {
+		names = append(names, entry.Name)
+	}
+	s.locations[key] = names
+	return names
+}
+
+// name resolves a single ID, falling back to an empty string.
+func (s *Service) name(ctx context.Context, id int64) string {
+	names, err := s.esiClient.ResolveNames(ctx, []int64{id})
+	if err != nil {
+		return ""
+	}
+	return names[id].Name
+}
+
+// parseFilter splits a comma separated list of place names.
+func parseFilter(text string) []string {
+	var filter []string
+	for _, part := range strings.Split(text, ",") {
+		if part = strings.ToLower(strings.TrimSpace(part)); part != "" {
+			filter = append(filter, part)
+		}
+	}
+	return filter
+}
+
+// matches reports whether any of the names is in the filter. "*" matches
+// every place whose names could be looked up.
+func matches(filter, names []string) bool {
+	if len(names) == 0 {
+		return false
+	}
+	for _, f := range filter {
+		if f == "*" {
+			return true
+		}
+		for _, name := range names {
+			if strings.ToLower(name) == f {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *Service) load() {
+	jsonfile.Load(s.path, "universe snapshot", &s.previous)
+}
+
+func (s *Service) save() {
+	jsonfile.Save(s.path, "universe snapshot", s.previous)
+}